@@ -0,0 +1,110 @@
+// Package blob stores uploaded files, such as KYC documents, by key. The
+// store is pluggable: Default keeps files in a directory on the local
+// filesystem and can be replaced by anything that satisfies Store.
+package blob
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob does not exist")
+	ErrInvalidKey = errors.New("blob key must be a relative path inside the store")
+)
+
+type Store interface {
+	Put(key string, data io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Default is the store used by the application. Configure points it at
+// BLOB_DIR.
+var Default Store = &FileStore{Root: "blobs"}
+
+// Configure replaces Default with a FileStore rooted at the directory in
+// the BLOB_DIR environment variable, if it is set.
+func Configure() {
+	if dir := os.Getenv("BLOB_DIR"); dir != "" {
+		Default = &FileStore{Root: dir}
+	}
+}
+
+// FileStore keeps each blob as a file under Root, with slashes in the key
+// becoming directories.
+type FileStore struct {
+	Root string
+}
+
+func (store *FileStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(store.Root, clean), nil
+}
+
+// Put writes data under key, replacing any blob already there. The data is
+// written to a temporary file first so that a failed upload leaves nothing
+// behind.
+func (store *FileStore) Put(key string, data io.Reader) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (store *FileStore) Get(key string) (io.ReadCloser, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (store *FileStore) Delete(key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -190,6 +190,164 @@ const docTemplate = `{
                 }
             }
         },
+        "/admin/staff": {
+            "post": {
+                "description": "Add an officer or supervisor to a branch. Supervisors approve postings that need an override.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Add a staff member",
+                "parameters": [
+                    {
+                        "description": "Staff member to be added",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateStaffRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Staff member added successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/staff/{id}": {
+            "get": {
+                "description": "Retrieve a staff member by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Get a staff member by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Staff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Staff member retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/staff/{id}/deactivate": {
+            "post": {
+                "description": "Stop a staff member from approving anything further",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Deactivate a staff member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Staff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Staff member deactivated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/customer/account/cheque/stop": {
+            "post": {
+                "description": "Place a stop-payment instruction on an unused cheque leaf",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cheques"
+                ],
+                "summary": "Stop payment of a cheque",
+                "parameters": [
+                    {
+                        "description": "Stop-payment instruction",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StopChequeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stop-payment placed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
         "/customer/account/deposit": {
             "post": {
                 "description": "Deposit money into an account",
@@ -233,8 +391,8 @@ const docTemplate = `{
             }
         },
         "/customer/account/nominee": {
-            "post": {
-                "description": "Add a nominee to an account",
+            "put": {
+                "description": "Replace the nominees of an account. Shares must add up to 100 percent. Nominees have no transaction rights.",
                 "consumes": [
                     "application/json"
                 ],
@@ -244,21 +402,21 @@ const docTemplate = `{
                 "tags": [
                     "Nominees"
                 ],
-                "summary": "Add a nominee to an account",
+                "summary": "Set the nominees of an account",
                 "parameters": [
                     {
-                        "description": "Add nominee request",
+                        "description": "Nominees of the account",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.AddNomineeRequest"
+                            "$ref": "#/definitions/handlers.SetNomineesRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Message: Nominee added",
+                        "description": "Nominees saved",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -313,7 +471,7 @@ const docTemplate = `{
         },
         "/customer/account/transfer": {
             "post": {
-                "description": "Transfer money between accounts",
+                "description": "Transfer money between accounts. The receiver is given either as an account number or as a VPA.",
                 "consumes": [
                     "application/json"
                 ],
@@ -432,16 +590,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/customer/account/{number}/nominee": {
+        "/customer/account/{number}/card": {
             "get": {
-                "description": "Retrieve all nominees by account number",
+                "description": "Retrieve all debit cards linked to an account with masked card numbers",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Customers"
+                    "Cards"
                 ],
-                "summary": "Get all nominees by account number",
+                "summary": "Get all cards by account number",
                 "parameters": [
                     {
                         "type": "string",
@@ -453,7 +611,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Nominees retrieved successfully",
+                        "description": "Cards retrieved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -469,16 +627,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/customer/account/{number}/nominee/{id}": {
-            "delete": {
-                "description": "Delete a nominee from an account by ID",
+        "/customer/account/{number}/cheque": {
+            "get": {
+                "description": "Retrieve every cheque leaf issued on an account with its status",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Nominees"
+                    "Cheques"
                 ],
-                "summary": "Delete a nominee from an account by ID",
+                "summary": "Get all cheques by account number",
                 "parameters": [
                     {
                         "type": "string",
@@ -486,18 +644,11 @@ const docTemplate = `{
                         "name": "number",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Nominee ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Message: Nominee deleted",
+                        "description": "Cheques retrieved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -513,16 +664,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/customer/account/{number}/transactions": {
+        "/customer/account/{number}/collect": {
             "get": {
-                "description": "Retrieve all transactions by account number",
+                "description": "Retrieve the collect requests an account has to pay or has raised, optionally filtered by status",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Collect Requests"
                 ],
-                "summary": "Get all transactions by account number",
+                "summary": "Get all collect requests by account number",
                 "parameters": [
                     {
                         "type": "string",
@@ -530,11 +681,24 @@ const docTemplate = `{
                         "name": "number",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "payer or payee",
+                        "name": "role",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, approved, declined or expired",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Transactions retrieved successfully",
+                        "description": "Collect requests retrieved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -550,28 +714,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/customer/{id}/account": {
+        "/customer/account/{number}/instruction": {
             "get": {
-                "description": "Retrieve all accounts by customer ID",
+                "description": "Retrieve the debit instructions raised on an account along with their approvals",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Instructions"
                 ],
-                "summary": "Get all accounts by customer ID",
+                "summary": "Get all instructions by account number",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Customer ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "number",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Account retrieved successfully",
+                        "description": "Instructions retrieved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -587,33 +751,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/account": {
-            "put": {
-                "description": "Update account information",
-                "consumes": [
-                    "application/json"
-                ],
+        "/customer/account/{number}/nominee": {
+            "get": {
+                "description": "Retrieve the nominees of an account with their relationship, date of birth and share",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Nominees"
                 ],
-                "summary": "Update account information",
+                "summary": "Get all nominees by account number",
                 "parameters": [
                     {
-                        "description": "Updated account object",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Account"
-                        }
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Account updated successfully",
+                    "200": {
+                        "description": "Nominees retrieved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -627,33 +786,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new account for a customer",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/customer/account/{number}/nominee/{id}": {
+            "delete": {
+                "description": "Delete a nominee from an account. The ID is that of the nominee entry, as listed by GET /customer/account/{number}/nominee, not the customer ID of the nominee. The shares of the remaining nominees are scaled up to add up to 100 percent.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Nominees"
                 ],
-                "summary": "Create a new account",
+                "summary": "Delete a nominee from an account by ID",
                 "parameters": [
                     {
-                        "description": "Account object to be created",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.CreateAccountRequest"
-                        }
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nominee entry ID, not a customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Account created successfully",
+                    "200": {
+                        "description": "Message: Nominee deleted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -667,26 +830,57 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete all accounts",
+            }
+        },
+        "/customer/account/{number}/statement": {
+            "get": {
+                "description": "Generate the statement of an account for a date range with opening, running and closing balances, as JSON, CSV or PDF",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv",
+                    "application/pdf"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Transactions"
                 ],
-                "summary": "Delete all accounts",
-                "responses": {
-                    "200": {
-                        "description": "message: All tables have been deleted",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "error: Bad request",
+                "summary": "Get an account statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day of the statement (YYYY-MM-DD), defaults to 30 days before the last day",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of the statement (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json, csv or pdf",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Statement generated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -695,28 +889,88 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/account/{id}": {
+        "/customer/account/{number}/transactions": {
             "get": {
-                "description": "Retrieve an account by its ID",
+                "description": "Retrieve all transactions by account number",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Transactions"
                 ],
-                "summary": "Get an account by ID",
+                "summary": "Get all transactions by account number",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Account ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "number",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Smallest amount",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Largest amount",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Type of transaction",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mode of payment",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, time or amount",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Account retrieved successfully",
+                        "description": "Transactions retrieved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -730,28 +984,108 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete an account by its ID",
+            }
+        },
+        "/customer/account/{number}/transactions/search": {
+            "get": {
+                "description": "Search the debits and credits of an account, each marked with its direction and counterparty",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Transactions"
                 ],
-                "summary": "Delete an account by ID",
+                "summary": "Search the transactions of an account",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Account ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "number",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Exact amount",
+                        "name": "amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Smallest amount",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Largest amount",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Type of transaction",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mode of payment",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reference number",
+                        "name": "reference",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Narration text",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, time or amount",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Branches deleted successfully",
+                        "description": "Transactions retrieved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -767,28 +1101,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/branch/{id}/account": {
-            "get": {
-                "description": "Retrieve all accounts by branch ID",
+        "/customer/card/block": {
+            "post": {
+                "description": "Block a card temporarily, or hot-list it if it was lost or stolen",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Accounts"
+                    "Cards"
                 ],
-                "summary": "Get all accounts by branch ID",
+                "summary": "Block a card",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Branch ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Card to be blocked",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BlockCardRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Account retrieved successfully",
+                        "description": "Card blocked",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -804,9 +1143,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/customer": {
+        "/customer/card/pin": {
             "put": {
-                "description": "Update customer information",
+                "description": "Change the PIN of a card after verifying the current one",
                 "consumes": [
                     "application/json"
                 ],
@@ -814,23 +1153,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Customers"
+                    "Cards"
                 ],
-                "summary": "Update customer information",
+                "summary": "Change a card's PIN",
                 "parameters": [
                     {
-                        "description": "Updated customer object",
+                        "description": "Old and new PIN",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Customer"
+                            "$ref": "#/definitions/handlers.ChangeCardPINRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Customer updated successfully",
+                    "200": {
+                        "description": "Message: PIN changed",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -844,9 +1183,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/customer/card/unblock": {
             "post": {
-                "description": "Create a new customer and associated account",
+                "description": "Reactivate a card the holder blocked, given its PIN. Wrong PINs count towards the PIN lockout. Cards locked after too many wrong PINs are unblocked by the branch, and hot-listed cards cannot be unblocked.",
                 "consumes": [
                     "application/json"
                 ],
@@ -854,23 +1195,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Customers"
+                    "Cards"
                 ],
-                "summary": "Create a new customer and account",
+                "summary": "Unblock a card",
                 "parameters": [
                     {
-                        "description": "Customer object to be created",
+                        "description": "Card to be unblocked",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.CreateCustomerRequest"
+                            "$ref": "#/definitions/handlers.UnblockCardRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Customer created successfully",
+                    "200": {
+                        "description": "Card unblocked",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -884,19 +1225,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete all customers",
+            }
+        },
+        "/customer/collect": {
+            "post": {
+                "description": "Ask the holder of an account number or VPA to pay an amount before the expiry",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Customers"
+                    "Collect Requests"
+                ],
+                "summary": "Raise a collect request",
+                "parameters": [
+                    {
+                        "description": "Collect request to be raised",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RaiseCollectRequestRequest"
+                        }
+                    }
                 ],
-                "summary": "Delete all customers",
                 "responses": {
-                    "200": {
-                        "description": "message: All Customers have been deleted",
+                    "201": {
+                        "description": "Collect request raised successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -912,28 +1269,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/customer/{id}": {
-            "get": {
-                "description": "Retrieve a customer by their ID",
+        "/customer/collect/{id}/approve": {
+            "post": {
+                "description": "Transfer the requested amount from the payer's account to the payee's account",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Customers"
+                    "Collect Requests"
                 ],
-                "summary": "Get a customer by ID",
+                "summary": "Approve a collect request",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Customer ID",
+                        "description": "Collect request ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Payer account and approving holder",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ApproveCollectRequestRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Customer retrieved successfully",
+                        "description": "Collect request approved",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -947,28 +1316,42 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a customer by their ID",
+            }
+        },
+        "/customer/collect/{id}/decline": {
+            "post": {
+                "description": "Decline a pending collect request without paying it",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Customers"
+                    "Collect Requests"
                 ],
-                "summary": "Delete a customer by ID",
+                "summary": "Decline a collect request",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Customer ID",
+                        "description": "Collect request ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Payer account",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RespondCollectRequestRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Customer deleted successfully",
+                        "description": "Collect request declined",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -984,35 +1367,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/super/bank": {
-            "get": {
-                "description": "Retrieve all banks",
+        "/customer/instruction/{id}/approve": {
+            "post": {
+                "description": "Approve a pending debit instruction. It is executed once every other holder has approved it.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Banks"
+                    "Instructions"
+                ],
+                "summary": "Approve an instruction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Instruction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Approving holder",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RespondInstructionRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all banks",
                 "responses": {
                     "200": {
-                        "description": "Banks retrieved successfully",
+                        "description": "Instruction approved",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "error\": \"Bad request",
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a bank with new information",
+            }
+        },
+        "/customer/instruction/{id}/reject": {
+            "post": {
+                "description": "Reject a pending debit instruction so that it is never executed",
                 "consumes": [
                     "application/json"
                 ],
@@ -1020,29 +1426,79 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Banks"
+                    "Instructions"
                 ],
-                "summary": "Update a bank",
+                "summary": "Reject an instruction",
                 "parameters": [
                     {
-                        "description": "Name of the bank",
-                        "name": "name",
+                        "type": "integer",
+                        "description": "Instruction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rejecting holder",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.RespondInstructionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Bank updated successfully",
+                    "200": {
+                        "description": "Instruction rejected",
                         "schema": {
-                            "$ref": "#/definitions/models.Bank"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "error\": \"Bad request",
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/customer/vpa": {
+            "put": {
+                "description": "Point a virtual payment address at another account held by the same customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPAs"
+                ],
+                "summary": "Change the account of a VPA",
+                "parameters": [
+                    {
+                        "description": "VPA and new account",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChangeVPAAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "VPA updated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1051,7 +1507,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new bank with the provided name",
+                "description": "Register a virtual payment address mapped to one of the customer's accounts",
                 "consumes": [
                     "application/json"
                 ],
@@ -1059,55 +1515,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Banks"
+                    "VPAs"
                 ],
-                "summary": "Create a new bank",
+                "summary": "Register a VPA",
                 "parameters": [
                     {
-                        "description": "Name of the bank",
-                        "name": "name",
+                        "description": "VPA to be registered",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.RegisterVPARequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Bank created successfully",
+                        "description": "VPA registered successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.Bank"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "error\": \"Bad request",
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete all banks",
+            }
+        },
+        "/customer/vpa/{address}": {
+            "get": {
+                "description": "Resolve a virtual payment address to the masked name of its holder",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Banks"
+                    "VPAs"
+                ],
+                "summary": "Resolve a VPA",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Virtual payment address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Delete all banks",
                 "responses": {
                     "200": {
-                        "description": "message\": \"All rows have been deleted",
+                        "description": "VPA resolved successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "error\": \"Bad request",
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1116,20 +1584,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/super/bank/{id}": {
+        "/customer/{id}/account": {
             "get": {
-                "description": "Retrieve a bank by its ID",
+                "description": "Retrieve all accounts by customer ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Banks"
+                    "Accounts"
                 ],
-                "summary": "Get a bank by ID",
+                "summary": "Get all accounts by customer ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Bank ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1137,33 +1605,86 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Bank retrieved successfully",
+                        "description": "Account retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.Bank"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "error\": \"Bad request",
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a bank by its ID",
+            }
+        },
+        "/customer/{id}/tds/certificate": {
+            "get": {
+                "description": "Summarise the interest paid to a customer in a financial year and the tax withheld from it per quarter, as JSON or PDF",
+                "produces": [
+                    "application/json",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "TDS"
+                ],
+                "summary": "Get a TDS certificate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Financial year such as 2026-27, defaults to the current one",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or pdf",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Certificate generated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/customer/{id}/vpa": {
+            "get": {
+                "description": "Retrieve all virtual payment addresses of a customer with the accounts they point to",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Banks"
+                    "VPAs"
                 ],
-                "summary": "Delete a bank by ID",
+                "summary": "Get all VPAs by customer ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Bank ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1171,100 +1692,4241 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Bank deleted successfully",
+                        "description": "VPAs retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.Bank"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "error\": \"Bad request",
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account": {
+            "put": {
+                "description": "Update account information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Update account information",
+                "parameters": [
+                    {
+                        "description": "Updated account object",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Account"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Account updated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new account for a customer. A minor's account needs an adult guardian who operates it. The account is frozen for debits until the KYC of every holder is verified. A nominee_id makes that customer the sole nominee with the whole share.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Create a new account",
+                "parameters": [
+                    {
+                        "description": "Account object to be created",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Account created successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
+            },
+            "delete": {
+                "description": "Delete all accounts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Delete all accounts",
+                "responses": {
+                    "200": {
+                        "description": "message: All tables have been deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/backdated": {
+            "post": {
+                "description": "Book a deposit, withdrawal or transfer with a value date on a business day that has already been closed. The supervisor must be an active supervisor of the account's bank and is recorded as the override.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Post a back-dated transaction",
+                "parameters": [
+                    {
+                        "description": "Back-dated posting",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BackDatedPostingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "message: Your Transaction has been completed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/hold": {
+            "post": {
+                "description": "Block funds on an account without moving them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Place a hold on an account",
+                "parameters": [
+                    {
+                        "description": "Hold to be placed",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PlaceHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Hold placed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/hold/{id}": {
+            "delete": {
+                "description": "Release an active hold so its amount becomes available again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Release a hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Hold released successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}": {
+            "get": {
+                "description": "Retrieve an account by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get an account by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/audit": {
+            "get": {
+                "description": "Retrieve every audited change made to an account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get the audit trail of an account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit trail retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/card": {
+            "post": {
+                "description": "Issue a debit card linked to an account to one of its holders, or a minor's guardian, with a PIN and ATM and POS limits",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cards"
+                ],
+                "summary": "Issue a debit card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Card to be issued",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IssueCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Card issued successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/chequebook": {
+            "get": {
+                "description": "Retrieve all cheque books issued on an account along with their leaves",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cheques"
+                ],
+                "summary": "Get all cheque books by account ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cheque books retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Issue a cheque book with a range of leaf numbers on an account to one of its holders, or a minor's guardian",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cheques"
+                ],
+                "summary": "Issue a cheque book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Holder and number of leaves",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IssueChequeBookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Cheque book issued successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/close": {
+            "post": {
+                "description": "Credit accrued interest, charge closure fees, pay out the net balance and mark the account closed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Close an account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Closure settlement details",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CloseAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account closed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/hold": {
+            "get": {
+                "description": "Retrieve all holds on an account along with its available balance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Get all holds on an account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Holds retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/majority-kyc": {
+            "post": {
+                "description": "Lift the debit freeze on the account of a holder who has attained majority once every holder has a verified KYC record (see /manager/kyc/{id}/review)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Complete KYC after majority",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Release details",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReactivateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account unfrozen successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/mode": {
+            "put": {
+                "description": "Set the mode of operation of a joint account. In jointly mode, debits above the threshold need approval from the other holders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Set an account's operating mode",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Operating mode",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetOperatingModeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Operating mode changed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/reactivate": {
+            "post": {
+                "description": "Return a dormant account to active once every holder has a verified KYC record (see /manager/kyc/{id}/review)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Reactivate a dormant account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reactivation details",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReactivateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account reactivated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/status": {
+            "put": {
+                "description": "Move an account to a new status if the transition is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Change the status of an account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChangeAccountStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account status changed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/sweep": {
+            "get": {
+                "description": "Retrieve the sweep rule of an account along with its linked deposit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sweeps"
+                ],
+                "summary": "Get a sweep rule by account ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sweep rule retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Sweep the balance above a threshold into a linked deposit in units, and break units back to cover debits",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sweeps"
+                ],
+                "summary": "Configure a sweep rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Sweep rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SaveSweepRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sweep rule saved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/sweep/disable": {
+            "post": {
+                "description": "Stop sweeping and break the whole linked deposit back into the account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sweeps"
+                ],
+                "summary": "Disable a sweep rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Actor",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DisableSweepRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sweep rule disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/account/{id}/sweep/run": {
+            "post": {
+                "description": "Sweep the surplus above the threshold into the linked deposit without waiting for end of day",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sweeps"
+                ],
+                "summary": "Run a sweep",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Amount swept",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/atm": {
+            "post": {
+                "description": "Install a new ATM at a branch with empty cassettes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ATMs"
+                ],
+                "summary": "Create a new ATM",
+                "parameters": [
+                    {
+                        "description": "ATM to be created",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateATMRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ATM created successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/atm/dispense/{id}/reverse": {
+            "post": {
+                "description": "Return the notes to the cassettes and credit the account back",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ATMs"
+                ],
+                "summary": "Reverse an ATM withdrawal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dispense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reversal reason",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReverseATMDispenseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Withdrawal reversed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/atm/{id}/reconcile": {
+            "get": {
+                "description": "Compare the cash that left an ATM with the withdrawals and reversals posted against it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ATMs"
+                ],
+                "summary": "Reconcile an ATM",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ATM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reconciliation computed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/atm/{id}/replenish": {
+            "post": {
+                "description": "Load notes into the cassettes of an ATM",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ATMs"
+                ],
+                "summary": "Replenish an ATM",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ATM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notes to be loaded",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReplenishATMRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ATM replenished successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/branch/{id}/account": {
+            "get": {
+                "description": "Retrieve all accounts by branch ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get all accounts by branch ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Account type",
+                        "name": "account_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Account status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, balance or account_type",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/branch/{id}/atm": {
+            "get": {
+                "description": "Retrieve all ATMs of a branch along with their cassettes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ATMs"
+                ],
+                "summary": "Get all ATMs by branch ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ATMs retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/branch/{id}/dashboard": {
+            "get": {
+                "description": "Deposits by product, account openings and closures per day, transaction volumes by type and mode per day, and the accounts with the largest balances",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboards"
+                ],
+                "summary": "Get the dashboard of a branch",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD), defaults to 30 days before the last day",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of accounts by balance, at most 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dashboard retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/branch/{id}/dormant": {
+            "get": {
+                "description": "Retrieve the dormant accounts and the unclaimed-deposits register of a branch",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get dormant and unclaimed accounts by branch ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/branch/{id}/kyc/due": {
+            "get": {
+                "description": "Retrieve the customers of a branch whose KYC has expired or falls due within 30 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "KYC"
+                ],
+                "summary": "Get customers due for re-KYC",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Customers due for re-KYC retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/branch/{id}/notification": {
+            "get": {
+                "description": "Retrieve the notifications sent to a branch, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Branches"
+                ],
+                "summary": "Get all notifications by branch ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notifications retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/card/unblock": {
+            "post": {
+                "description": "Reactivate a blocked card, including one locked after too many wrong PINs, and reset its wrong PIN count. Hot-listed cards cannot be unblocked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cards"
+                ],
+                "summary": "Unblock a card at the branch",
+                "parameters": [
+                    {
+                        "description": "Card to be unblocked",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReleaseCardBlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Card unblocked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/cheque/clear": {
+            "post": {
+                "description": "Present a cheque against the drawer's account and either pay it or record it as bounced",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cheques"
+                ],
+                "summary": "Clear a cheque",
+                "parameters": [
+                    {
+                        "description": "Cheque to be cleared",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ClearChequeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cheque paid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "error: Cheque bounced",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/claim/{id}": {
+            "get": {
+                "description": "Retrieve a deceased claim with its documents and payouts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "Get a deceased claim by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Claim ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Claim retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/claim/{id}/approve": {
+            "post": {
+                "description": "Pay the balance of the deceased's sole accounts out to their nominees by share, close those accounts and release frozen joint accounts. Each nominee entry is paid by transfer to an account or in cash; nominees without a payout are paid in cash. Accounts that cannot be settled are listed on the claim, which stays partially settled and can be approved again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "Approve a deceased claim",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Claim ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Approving manager",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ApproveDeceasedClaimRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Claim settled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/claim/{id}/document": {
+            "post": {
+                "description": "Record a document submitted for a deceased claim, such as the death certificate or claim form",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "Add a claim document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Claim ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Claim document",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AddClaimDocumentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Document added successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer": {
+            "put": {
+                "description": "Update customer information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Update customer information",
+                "parameters": [
+                    {
+                        "description": "Updated customer object",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Customer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Customer updated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new customer and associated account. A minor's account needs an adult guardian who operates it. The account is frozen for debits until the customer's KYC is verified. The PAN must be valid and unique in the bank, and customers who look like existing ones are refused unless staff override with a justification.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Create a new customer and account",
+                "parameters": [
+                    {
+                        "description": "Customer object to be created",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateCustomerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Customer created successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "error: Possible duplicates, with the candidates",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete all customers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Delete all customers",
+                "responses": {
+                    "200": {
+                        "description": "message: All Customers have been deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer/duplicates": {
+            "post": {
+                "description": "Fuzzy-match the name, date of birth and phone number of a prospective customer against the living customers of the branch's bank and return the likely matches, best first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Find possible duplicate customers",
+                "parameters": [
+                    {
+                        "description": "Prospective customer",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FindDuplicateCustomersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Candidates retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer/{id}": {
+            "get": {
+                "description": "Retrieve a customer by their ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get a customer by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Customer retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a customer by their ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Delete a customer by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Customer deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer/{id}/deceased": {
+            "post": {
+                "description": "Mark a customer deceased and open a claim. Sole accounts are frozen for debits, jointly operated accounts are frozen and survivorship accounts pass to the other holders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "Report the death of a customer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Date of death",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportDeathRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Claim opened successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer/{id}/kyc": {
+            "get": {
+                "description": "Retrieve the KYC documents submitted for a customer, latest first, with their review status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "KYC"
+                ],
+                "summary": "Get KYC documents by customer ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "KYC documents retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Upload the scan of a customer's identity document. It is stored in the blob store and awaits review.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "KYC"
+                ],
+                "summary": "Submit a KYC document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "passport, aadhaar, voter_id, driving_licence or pan",
+                        "name": "document_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Document number",
+                        "name": "document_number",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor",
+                        "name": "actor",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Document scan, at most 5 MB",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "KYC document submitted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer/{id}/merge": {
+            "post": {
+                "description": "Merge the customer merged_customer_id into the customer in the path. Account links, nominations, KYC documents, VPAs and tax records move to the survivor; fields that differ (name, pan, dob, phone, address, branch_id) take the value chosen for them; the merged customer is kept as a tombstone pointing at the survivor. With preview set, the merge is worked out and reported without being saved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Merge a duplicate customer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Surviving customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MergeCustomersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Customers merged successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer/{id}/tds": {
+            "get": {
+                "description": "Retrieve every interest credit of a customer in a financial year with the rate applied and the tax withheld",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TDS"
+                ],
+                "summary": "Get the tax ledger of a customer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Financial year such as 2026-27, defaults to the current one",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ledger retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/customer/{id}/tds/exemption": {
+            "put": {
+                "description": "Record a form 15G or 15H declaration of a customer for a financial year, such as 2026-27. No tax is withheld from interest credited afterwards in that year.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TDS"
+                ],
+                "summary": "Record a tax exemption declaration",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Declaration",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SaveTDSExemptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Declaration recorded successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/kyc/{id}/file": {
+            "get": {
+                "description": "Download the file uploaded with a KYC document",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "KYC"
+                ],
+                "summary": "Download a KYC document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "KYC record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Document scan",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/kyc/{id}/review": {
+            "post": {
+                "description": "Verify or reject a pending KYC document. Verification sets the re-KYC due date and unfreezes accounts whose holders are all verified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "KYC"
+                ],
+                "summary": "Review a KYC document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "KYC record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReviewKYCRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "KYC document reviewed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/bank": {
+            "get": {
+                "description": "Retrieve all banks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Banks"
+                ],
+                "summary": "Get all banks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id or name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Banks retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a bank with new information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Banks"
+                ],
+                "summary": "Update a bank",
+                "parameters": [
+                    {
+                        "description": "Name of the bank",
+                        "name": "name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Bank updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Bank"
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new bank with the provided name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Banks"
+                ],
+                "summary": "Create a new bank",
+                "parameters": [
+                    {
+                        "description": "Name of the bank",
+                        "name": "name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Bank created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Bank"
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete all banks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Banks"
+                ],
+                "summary": "Delete all banks",
+                "responses": {
+                    "200": {
+                        "description": "message\": \"All rows have been deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/bank/{id}": {
+            "get": {
+                "description": "Retrieve a bank by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Banks"
+                ],
+                "summary": "Get a bank by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bank retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Bank"
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a bank by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Banks"
+                ],
+                "summary": "Delete a bank by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bank deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Bank"
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/bank/{id}/bod": {
+            "post": {
+                "description": "Open the day after a business day closed by end of day",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Business Day"
+                ],
+                "summary": "Start the next business day",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Actor",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BusinessDayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Business day started successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/bank/{id}/business-day": {
+            "get": {
+                "description": "Retrieve the business date of a bank and whether it is open, in end of day or closed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Business Day"
+                ],
+                "summary": "Get the business day of a bank",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Business day retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/bank/{id}/dashboard": {
+            "get": {
+                "description": "Deposits by product, account openings and closures per day, transaction volumes by type and mode per day, and the accounts with the largest balances across all branches of a bank",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboards"
+                ],
+                "summary": "Get the dashboard of a bank",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD), defaults to 30 days before the last day",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of accounts by balance, at most 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dashboard retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/bank/{id}/eod": {
+            "get": {
+                "description": "Retrieve the end of day runs of a bank, latest first, with their completed steps",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Business Day"
+                ],
+                "summary": "Get end of day runs by bank ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "End of day runs retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Close the business day of a bank: accrue interest, run standing instructions and dormancy, and generate the day-end report. A failed run resumes from the step that failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Business Day"
+                ],
+                "summary": "Run end of day",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Actor",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BusinessDayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "End of day completed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error: A step failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/bank/{id}/eod/report": {
+            "get": {
+                "description": "Retrieve the report generated by end of day for a business date",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Business Day"
+                ],
+                "summary": "Get a day-end report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Bank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Business date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Day-end report retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/ctr": {
+            "get": {
+                "description": "Retrieve the items flagged for cash transaction reporting in a month",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cash Transaction Reports"
+                ],
+                "summary": "Get flagged cash transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month (YYYY-MM)",
+                        "name": "month",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, reportable, dismissed or filed",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Flagged items retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/ctr/report": {
+            "post": {
+                "description": "Scan the month, then file every reportable item and download the report in its fixed-width format. All flagged items must have been reviewed.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Cash Transaction Reports"
+                ],
+                "summary": "Generate the monthly cash transaction report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month (YYYY-MM)",
+                        "name": "month",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/ctr/rule": {
+            "get": {
+                "description": "Retrieve all cash transaction reporting rules",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cash Transaction Reports"
+                ],
+                "summary": "Get all cash transaction reporting rules",
+                "responses": {
+                    "200": {
+                        "description": "Rules retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Flag cash transactions of a customer at or above a threshold, either singly or added up per day across all their accounts. Without an id a new rule is created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cash Transaction Reports"
+                ],
+                "summary": "Save a cash transaction reporting rule",
+                "parameters": [
+                    {
+                        "description": "Rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SaveCTRRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rule saved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/ctr/{id}/review": {
+            "post": {
+                "description": "Mark a flagged item reportable, to include it in the monthly report, or dismissed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cash Transaction Reports"
+                ],
+                "summary": "Review a flagged cash transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Flagged item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReviewCTRFlagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Flagged item reviewed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/dashboard": {
+            "get": {
+                "description": "Deposits by product, account openings and closures per day, transaction volumes by type and mode per day, and the accounts with the largest balances across all banks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboards"
+                ],
+                "summary": "Get the system-wide dashboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD), defaults to 30 days before the last day",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of accounts by balance, at most 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dashboard retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/discrepancy/{id}/approve": {
+            "post": {
+                "description": "As a supervisor, approve a requested repair. The supervisor must be an active supervisor of the account's bank other than the requester. The account is checked again and the adjustment entry posted only if the discrepancy is unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Approve a balance repair",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Discrepancy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Supervisor",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ApproveBalanceRepairRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Repair approved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/discrepancy/{id}/repair": {
+            "post": {
+                "description": "Ask for an adjustment entry that sets the balance of the account to the amount its transactions add up to. A supervisor other than the requester must approve it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Request a balance repair",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Discrepancy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Repair request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RequestBalanceRepairRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Repair requested successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/reconciliation": {
+            "get": {
+                "description": "Retrieve the balance reconciliation runs, latest first, with their account and discrepancy counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Get all reconciliation runs",
+                "responses": {
+                    "200": {
+                        "description": "Reconciliation runs retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Replay the transactions of every account from its last reconciled snapshot and report the accounts whose balance does not match",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Reconcile balances",
+                "parameters": [
+                    {
+                        "description": "Actor",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReconciliationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reconciliation completed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/super/reconciliation/{id}": {
+            "get": {
+                "description": "Retrieve a reconciliation run with each discrepancy found: the account, the expected and actual balances and the first transaction that may have diverged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Get a reconciliation run by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reconciliation run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reconciliation run retrieved successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/terminal/atm/{id}/withdraw": {
+            "post": {
+                "description": "Verify the card and PIN, dispense notes by denomination and debit the account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ATMs"
+                ],
+                "summary": "Withdraw cash at an ATM",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ATM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Withdrawal request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ATMWithdrawalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cash dispensed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/terminal/card/authorize": {
+            "post": {
+                "description": "Verify the PIN and limits of a card and either debit the account or place a hold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cards"
+                ],
+                "summary": "Authorize a card payment",
+                "parameters": [
+                    {
+                        "description": "Authorization request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthorizeCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Authorization approved",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/terminal/card/capture/{id}": {
+            "post": {
+                "description": "Release the hold placed by a card authorization and debit the account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cards"
+                ],
+                "summary": "Capture a card authorization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Authorization captured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.ATMWithdrawalRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "card_number",
+                "pin"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "card_number": {
+                    "type": "string"
+                },
+                "pin": {
+                    "type": "string"
+                },
+                "simulate_dispense_failure": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.AddClaimDocumentRequest": {
+            "type": "object",
+            "required": [
+                "document_type",
+                "reference"
+            ],
+            "properties": {
+                "document_type": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ApproveBalanceRepairRequest": {
+            "type": "object",
+            "required": [
+                "supervisor_id"
+            ],
+            "properties": {
+                "supervisor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ApproveCollectRequestRequest": {
+            "type": "object",
+            "required": [
+                "customer_id",
+                "payer_account_number"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "integer"
+                },
+                "payer_account_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ApproveDeceasedClaimRequest": {
+            "type": "object",
+            "required": [
+                "actor"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "payouts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ClaimPayoutRequest"
+                    }
+                }
+            }
+        },
+        "handlers.AuthorizeCardRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "card_number",
+                "channel",
+                "pin",
+                "terminal_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "capture": {
+                    "type": "boolean"
+                },
+                "card_number": {
+                    "type": "string"
+                },
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "ATM",
+                        "POS"
+                    ]
+                },
+                "pin": {
+                    "type": "string"
+                },
+                "terminal_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.BackDatedPostingRequest": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "mode_of_payment",
+                "supervisor_id",
+                "type_of_transaction",
+                "value_date"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "initiated_by": {
+                    "type": "integer"
+                },
+                "mode_of_payment": {
+                    "type": "string"
+                },
+                "receiver_account_number": {
+                    "type": "string"
+                },
+                "supervisor_id": {
+                    "type": "integer"
+                },
+                "type_of_transaction": {
+                    "type": "string",
+                    "enum": [
+                        "Deposit",
+                        "Withdraw",
+                        "Transfer"
+                    ]
+                },
+                "value_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.BlockCardRequest": {
+            "type": "object",
+            "required": [
+                "card_number"
+            ],
+            "properties": {
+                "card_number": {
+                    "type": "string"
+                },
+                "hot_list": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.BusinessDayRequest": {
+            "type": "object",
+            "required": [
+                "actor"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CassetteLoad": {
+            "type": "object",
+            "required": [
+                "count",
+                "denomination"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "denomination": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ChangeAccountStatusRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "reason",
+                "status"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "dormant",
+                        "debit_frozen",
+                        "credit_frozen",
+                        "total_frozen"
+                    ]
+                }
+            }
+        },
+        "handlers.ChangeCardPINRequest": {
+            "type": "object",
+            "required": [
+                "card_number",
+                "new_pin",
+                "old_pin"
+            ],
+            "properties": {
+                "card_number": {
+                    "type": "string"
+                },
+                "new_pin": {
+                    "type": "string"
+                },
+                "old_pin": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ChangeVPAAccountRequest": {
+            "type": "object",
+            "required": [
+                "account_number",
+                "address",
+                "customer_id"
+            ],
+            "properties": {
+                "account_number": {
+                    "type": "string"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ClaimPayoutRequest": {
+            "type": "object",
+            "required": [
+                "nominee_id",
+                "payout_mode"
+            ],
+            "properties": {
+                "nominee_id": {
+                    "type": "integer"
+                },
+                "payout_account_number": {
+                    "type": "string"
+                },
+                "payout_mode": {
+                    "type": "string",
+                    "enum": [
+                        "transfer",
+                        "cash"
+                    ]
+                }
+            }
+        },
+        "handlers.ClearChequeRequest": {
+            "type": "object",
+            "required": [
+                "account_number",
+                "amount",
+                "cheque_date",
+                "cheque_number",
+                "payee"
+            ],
+            "properties": {
+                "account_number": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "cheque_date": {
+                    "type": "string"
+                },
+                "cheque_number": {
+                    "type": "integer"
+                },
+                "payee": {
+                    "type": "string"
+                },
+                "payee_account_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CloseAccountRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "payout_mode",
+                "reason"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "payout_account_number": {
+                    "type": "string"
+                },
+                "payout_mode": {
+                    "type": "string",
+                    "enum": [
+                        "transfer",
+                        "cash"
+                    ]
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateATMRequest": {
+            "type": "object",
+            "required": [
+                "branch_id",
+                "location"
+            ],
+            "properties": {
+                "branch_id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateAccountRequest": {
+            "type": "object",
+            "required": [
+                "account_type",
+                "balance",
+                "customer_id"
+            ],
+            "properties": {
+                "account_type": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "guardian_id": {
+                    "type": "integer"
+                },
+                "joint_holder_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "nominee_id": {
+                    "description": "NomineeID names an existing customer as the sole nominee, as before\nnominees had shares. Further nominees are set through SetNominees.",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CreateCustomerRequest": {
+            "type": "object",
+            "required": [
+                "account_type",
+                "address",
+                "age",
+                "balance",
+                "branch_id",
+                "dob",
+                "name",
+                "pan",
+                "phone"
+            ],
+            "properties": {
+                "account_type": {
+                    "type": "string"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "age": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "branch_id": {
+                    "type": "integer"
+                },
+                "dob": {
+                    "type": "string"
+                },
+                "guardian_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "override_by": {
+                    "description": "OverrideBy and OverrideJustification create the customer in spite of\npossible duplicates.",
+                    "type": "string"
+                },
+                "override_justification": {
+                    "type": "string"
+                },
+                "pan": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CreateStaffRequest": {
+            "type": "object",
+            "required": [
+                "branch_id",
+                "name",
+                "role"
+            ],
+            "properties": {
+                "branch_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "officer",
+                        "supervisor"
+                    ]
+                }
+            }
+        },
+        "handlers.DisableSweepRuleRequest": {
+            "type": "object",
+            "required": [
+                "actor"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.FindDuplicateCustomersRequest": {
+            "type": "object",
+            "required": [
+                "branch_id",
+                "dob",
+                "name",
+                "phone"
+            ],
+            "properties": {
+                "branch_id": {
+                    "type": "integer"
+                },
+                "dob": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.IssueCardRequest": {
+            "type": "object",
+            "required": [
+                "customer_id",
+                "pin"
+            ],
+            "properties": {
+                "atm_limit": {
+                    "type": "number"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "pin": {
+                    "type": "string"
+                },
+                "pos_limit": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.IssueChequeBookRequest": {
+            "type": "object",
+            "required": [
+                "customer_id",
+                "leaves"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "integer"
+                },
+                "leaves": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                }
+            }
+        },
+        "handlers.MergeCustomersRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "merged_customer_id"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "choices": {
+                    "description": "Choices picks \"survivor\" or \"merged\" for each field whose values differ.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "merged_customer_id": {
+                    "type": "integer"
+                },
+                "preview": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.NomineeRequest": {
+            "type": "object",
+            "required": [
+                "relationship",
+                "share"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "integer"
+                },
+                "date_of_birth": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "relationship": {
+                    "type": "string"
+                },
+                "share": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.PlaceHoldRequest": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "reason"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "expiry": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "source_reference": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RaiseCollectRequestRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "payee_account_number"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "expiry": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "payee_account_number": {
+                    "type": "string"
+                },
+                "payer_account_number": {
+                    "type": "string"
+                },
+                "payer_vpa": {
+                    "type": "string"
+                }
             }
-        }
-    },
-    "definitions": {
-        "handlers.AddNomineeRequest": {
+        },
+        "handlers.ReactivateAccountRequest": {
+            "type": "object",
+            "required": [
+                "actor"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReconciliationRequest": {
+            "type": "object",
+            "required": [
+                "actor"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RegisterVPARequest": {
             "type": "object",
             "required": [
                 "account_number",
-                "nominee_id"
+                "customer_id",
+                "name"
             ],
             "properties": {
                 "account_number": {
                     "type": "string"
                 },
-                "nominee_id": {
+                "customer_id": {
                     "type": "integer"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.CreateAccountRequest": {
+        "handlers.ReleaseCardBlockRequest": {
             "type": "object",
             "required": [
-                "account_type",
-                "balance",
-                "customer_id"
+                "actor",
+                "card_number"
             ],
             "properties": {
-                "account_type": {
+                "actor": {
                     "type": "string"
                 },
-                "balance": {
-                    "type": "number"
+                "card_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReplenishATMRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "cassettes"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "cassettes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.CassetteLoad"
+                    }
+                }
+            }
+        },
+        "handlers.ReportDeathRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "date_of_death"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "date_of_death": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RequestBalanceRepairRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "note"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
                 },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RespondCollectRequestRequest": {
+            "type": "object",
+            "required": [
+                "payer_account_number"
+            ],
+            "properties": {
+                "payer_account_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RespondInstructionRequest": {
+            "type": "object",
+            "required": [
+                "customer_id"
+            ],
+            "properties": {
                 "customer_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "handlers.ReverseATMDispenseRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReviewCTRFlagRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "status"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
                 },
-                "nominee_id": {
-                    "type": "integer"
+                "note": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "reportable",
+                        "dismissed"
+                    ]
                 }
             }
         },
-        "handlers.CreateCustomerRequest": {
+        "handlers.ReviewKYCRequest": {
             "type": "object",
             "required": [
-                "account_type",
-                "address",
-                "age",
-                "balance",
-                "branch_id",
-                "dob",
-                "name",
-                "pan",
-                "phone"
+                "actor",
+                "status"
             ],
             "properties": {
-                "account_type": {
+                "actor": {
                     "type": "string"
                 },
-                "address": {
+                "note": {
                     "type": "string"
                 },
-                "age": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "verified",
+                        "rejected"
+                    ]
+                }
+            }
+        },
+        "handlers.SaveCTRRuleRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name",
+                "threshold"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "balance": {
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "single",
+                        "daily"
+                    ]
+                },
+                "modes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "threshold": {
                     "type": "number"
+                }
+            }
+        },
+        "handlers.SaveSweepRuleRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "threshold",
+                "unit"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
                 },
-                "branch_id": {
-                    "type": "integer"
+                "threshold": {
+                    "type": "number"
                 },
-                "dob": {
+                "unit": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.SaveTDSExemptionRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "financialYear",
+                "form"
+            ],
+            "properties": {
+                "actor": {
                     "type": "string"
                 },
-                "name": {
+                "financialYear": {
                     "type": "string"
                 },
-                "pan": {
+                "form": {
+                    "type": "string",
+                    "enum": [
+                        "15G",
+                        "15H"
+                    ]
+                }
+            }
+        },
+        "handlers.SetNomineesRequest": {
+            "type": "object",
+            "required": [
+                "account_number"
+            ],
+            "properties": {
+                "account_number": {
                     "type": "string"
                 },
-                "phone": {
+                "nominees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.NomineeRequest"
+                    }
+                }
+            }
+        },
+        "handlers.SetOperatingModeRequest": {
+            "type": "object",
+            "required": [
+                "actor",
+                "mode"
+            ],
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "either_or_survivor",
+                        "former_or_survivor",
+                        "jointly"
+                    ]
+                },
+                "threshold": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "handlers.StopChequeRequest": {
+            "type": "object",
+            "required": [
+                "account_number",
+                "cheque_number",
+                "reason"
+            ],
+            "properties": {
+                "account_number": {
+                    "type": "string"
+                },
+                "cheque_number": {
                     "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.UnblockCardRequest": {
+            "type": "object",
+            "required": [
+                "card_number",
+                "pin"
+            ],
+            "properties": {
+                "card_number": {
+                    "type": "string"
+                },
+                "pin": {
+                    "type": "string"
                 }
             }
         },
@@ -1280,6 +5942,10 @@ const docTemplate = `{
                 "balance": {
                     "type": "number"
                 },
+                "blockReason": {
+                    "description": "BlockReason is set while a KYC workflow holds the account: dormancy,\na minor attaining majority or missing KYC. Only that workflow clears\nit, so staff cannot reactivate the account around it.",
+                    "type": "string"
+                },
                 "branch": {
                     "$ref": "#/definitions/models.Branch"
                 },
@@ -1292,9 +5958,31 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.Customer"
                     }
                 },
+                "dormantSince": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "jointApprovalThreshold": {
+                    "type": "number"
+                },
+                "openedAt": {
+                    "type": "string"
+                },
+                "openingBalance": {
+                    "description": "OpeningBalance is the balance the account was opened with, which\nreconciliation replays its transactions from.",
+                    "type": "number"
+                },
+                "operatingMode": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusReason": {
+                    "type": "string"
+                },
                 "transaction": {
                     "type": "array",
                     "items": {
@@ -1367,18 +6055,35 @@ const docTemplate = `{
                 "age": {
                     "type": "integer"
                 },
+                "bankID": {
+                    "description": "BankID is the bank of the branch, kept so that a PAN is unique per bank.",
+                    "type": "integer"
+                },
                 "branch": {
                     "$ref": "#/definitions/models.Branch"
                 },
                 "branchID": {
                     "type": "integer"
                 },
+                "deceasedOn": {
+                    "type": "string"
+                },
                 "dob": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "kycverifiedAt": {
+                    "type": "string"
+                },
+                "mergedAt": {
+                    "type": "string"
+                },
+                "mergedInto": {
+                    "description": "MergedInto points a duplicate record merged by MergeCustomers at the\ncustomer it was merged into.",
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -1402,20 +6107,39 @@ const docTemplate = `{
                 "amount": {
                     "type": "number"
                 },
+                "bookingDate": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "initiatedBy": {
+                    "type": "integer"
+                },
                 "modeOfPayment": {
                     "type": "string"
                 },
+                "overrideBy": {
+                    "type": "string"
+                },
                 "receiverAccountNumber": {
                     "type": "string"
                 },
+                "receiverVPA": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
                 "time": {
                     "type": "string"
                 },
                 "typeOfTransaction": {
                     "type": "string"
+                },
+                "valueDate": {
+                    "description": "ValueDate is the day the posting takes effect and BookingDate the\nbusiness day it was booked on. OverrideBy names the supervisor who\nallowed a value date on a day that was already closed; such postings\nare made only through PostBackDated.",
+                    "type": "string"
                 }
             }
         }
@@ -2,7 +2,16 @@ module github.com/shouryagautam/bankdeploy
 
 go 1.21.6
 
-require github.com/go-pg/pg/v10 v10.12.0
+require (
+	github.com/gin-gonic/gin v1.9.1
+	github.com/go-pg/pg/v10 v10.12.0
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	github.com/swaggo/files v1.0.1
+	github.com/swaggo/gin-swagger v1.6.0
+	github.com/swaggo/swag v1.16.3
+	golang.org/x/crypto v0.19.0
+)
 
 require (
 	github.com/KyleBanks/depth v1.2.1 // indirect
@@ -33,9 +42,6 @@ require (
 	github.com/pelletier/go-toml/v2 v2.1.1 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
-	github.com/swaggo/files v1.0.1 // indirect
-	github.com/swaggo/gin-swagger v1.6.0 // indirect
-	github.com/swaggo/swag v1.16.3 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	github.com/ugorji/go/codec v1.2.12 // indirect
 	github.com/urfave/cli/v2 v2.27.1 // indirect
@@ -51,18 +57,14 @@ require (
 )
 
 require (
-	github.com/gin-gonic/gin v1.9.1
 	github.com/go-pg/pg v8.0.7+incompatible
 	github.com/go-pg/zerochecker v0.2.0 // indirect
-	github.com/google/uuid v1.6.0
 	github.com/jinzhu/inflection v1.0.0 // indirect
-	github.com/joho/godotenv v1.5.1
 	github.com/tmthrgd/go-hex v0.0.0-20190904060850-447a3041c3bc // indirect
 	github.com/vmihailenco/bufpool v0.1.11 // indirect
 	github.com/vmihailenco/msgpack/v5 v5.3.4 // indirect
 	github.com/vmihailenco/tagparser v0.1.2 // indirect
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
-	golang.org/x/crypto v0.19.0 // indirect
 	golang.org/x/sys v0.17.0 // indirect
 	mellium.im/sasl v0.3.1 // indirect
 )
@@ -11,15 +11,19 @@ import (
 
 // CreateAccountRequest represents the request structure for creating a new account.
 type CreateAccountRequest struct {
-	CustomerID  uint    `json:"customer_id" binding:"required"`
-	Balance     float64 `json:"balance" binding:"required"`
-	AccountType string  `json:"account_type" binding:"required"`
-	NomineeID   uint    `json:"nominee_id"`
+	CustomerID     uint    `json:"customer_id" binding:"required"`
+	Balance        float64 `json:"balance" binding:"required"`
+	AccountType    string  `json:"account_type" binding:"required"`
+	JointHolderIDs []uint  `json:"joint_holder_ids"`
+	GuardianID     uint    `json:"guardian_id"`
+	// NomineeID names an existing customer as the sole nominee, as before
+	// nominees had shares. Further nominees are set through SetNominees.
+	NomineeID uint `json:"nominee_id"`
 }
 
 // CreateAccount creates a new account for a customer.
 // @Summary Create a new account
-// @Description Create a new account for a customer
+// @Description Create a new account for a customer. A minor's account needs an adult guardian who operates it. The account is frozen for debits until the KYC of every holder is verified. A nominee_id makes that customer the sole nominee with the whole share.
 // @Tags Accounts
 // @Accept json
 // @Produce json
@@ -40,6 +44,26 @@ func CreateAccount(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
+	if err := customer.CheckNotMerged(); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	minor, err := customer.IsMinor()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if minor {
+		if err := models.CheckGuardian(customer, input.GuardianID); err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		if len(input.JointHolderIDs) > 0 {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "a minor's account cannot have joint holders"})
+			return
+		}
+	}
 
 	account := models.Account{
 		BranchID:    customer.BranchID,
@@ -48,13 +72,39 @@ func CreateAccount(context *gin.Context) {
 	}
 	account.Customer = append(account.Customer, customer)
 
+	for _, holderID := range input.JointHolderIDs {
+		holder, err := models.FindCustomerByID(holderID)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		if err := holder.CheckNotMerged(); err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		account.Customer = append(account.Customer, holder)
+	}
+
 	if input.NomineeID != 0 {
+		if input.NomineeID == customer.ID || containsUint(input.JointHolderIDs, input.NomineeID) {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "an account holder cannot be a nominee of the same account"})
+			return
+		}
 		nominee, err := models.FindCustomerByID(input.NomineeID)
 		if err != nil {
 			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 			return
 		}
-		account.Customer = append(account.Customer, nominee)
+		if err := nominee.CheckNotMerged(); err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+
+	account.Status, account.StatusReason, err = models.OpeningStatus(append([]uint{customer.ID}, input.JointHolderIDs...)...)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
 	}
 
 	savedAccount, err := account.Save()
@@ -66,6 +116,7 @@ func CreateAccount(context *gin.Context) {
 	mapping := models.CustomerToAccount{
 		CustomerID: customer.ID,
 		AccountID:  savedAccount.ID,
+		Role:       models.ROLE_PRIMARY,
 	}
 	err = mapping.Save()
 	if err != nil {
@@ -73,10 +124,11 @@ func CreateAccount(context *gin.Context) {
 		return
 	}
 
-	if input.NomineeID != 0 {
+	for _, holderID := range input.JointHolderIDs {
 		mapping := models.CustomerToAccount{
-			CustomerID: input.NomineeID,
+			CustomerID: holderID,
 			AccountID:  savedAccount.ID,
+			Role:       models.ROLE_JOINT,
 		}
 		err = mapping.Save()
 		if err != nil {
@@ -85,29 +137,80 @@ func CreateAccount(context *gin.Context) {
 		}
 	}
 
+	if minor {
+		mapping := models.CustomerToAccount{
+			CustomerID: input.GuardianID,
+			AccountID:  savedAccount.ID,
+			Role:       models.ROLE_GUARDIAN,
+		}
+		err = mapping.Save()
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"err": err.Error()})
+			return
+		}
+	}
+
+	if input.NomineeID != 0 {
+		_, err = models.SetNominees(savedAccount.AccountNumber, []models.CustomerToAccount{{
+			NomineeCustomerID: input.NomineeID,
+			Relationship:      "not stated",
+			SharePercentage:   100,
+		}})
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"err": err.Error()})
+			return
+		}
+	}
+
 	context.JSON(http.StatusCreated, map[string]interface{}{"Account": savedAccount})
 }
 
+func containsUint(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAllAccountsByBranchID retrieves all accounts by branch ID.
 // @Summary Get all accounts by branch ID
 // @Description Retrieve all accounts by branch ID
 // @Tags Accounts
 // @Produce json
 // @Param id path int true "Branch ID"
+// @Param account_type query string false "Account type"
+// @Param status query string false "Account status"
+// @Param limit query int false "Page size, at most 500"
+// @Param cursor query string false "Cursor returned with the previous page"
+// @Param sort query string false "id, balance or account_type"
+// @Param order query string false "asc or desc"
 // @Success 200 {object} map[string]interface{} "Account retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
 // @Router /manager/branch/{id}/account [get]
 func GetAllAccountsByBranchID(context *gin.Context) {
 	id := context.Param("id")
 	ID, _ := strconv.ParseUint(id, 10, 0)
-	accounts, err := models.FindAllAccountsByBranchID(uint(ID))
 
+	opts, err := listOptions(context)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, map[string]interface{}{"Account": accounts})
+	filter := models.AccountFilter{
+		AccountType: context.Query("account_type"),
+		Status:      context.Query("status"),
+	}
+
+	accounts, page, err := models.FindAllAccountsByBranchID(uint(ID), filter, opts)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Account": accounts, "Page": page})
 }
 
 // GetAccountById retrieves an account by its ID.
@@ -194,26 +297,43 @@ func DeleteAllAccounts(context *gin.Context) {
 	context.JSON(http.StatusOK, map[string]interface{}{"message": "All tables have been deleted"})
 }
 
-// DeleteAccountByID deletes an account by its ID.
-// @Summary Delete an account by ID
-// @Description Delete an account by its ID
+// CloseAccountRequest represents the request structure for closing an account.
+type CloseAccountRequest struct {
+	PayoutMode          string    `json:"payout_mode" binding:"required,oneof=transfer cash"`
+	PayoutAccountNumber uuid.UUID `json:"payout_account_number"`
+	Reason              string    `json:"reason" binding:"required"`
+	Actor               string    `json:"actor" binding:"required"`
+}
+
+// CloseAccount settles and closes an account.
+// @Summary Close an account
+// @Description Credit accrued interest, charge closure fees, pay out the net balance and mark the account closed
 // @Tags Accounts
+// @Accept json
 // @Produce json
 // @Param id path int true "Account ID"
-// @Success 200 {object} map[string]interface{} "Branches deleted successfully"
+// @Param body body CloseAccountRequest true "Closure settlement details"
+// @Success 200 {object} map[string]interface{} "Account closed successfully"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
-// @Router /manager/account/{id} [delete]
-func DeleteAccountByID(context *gin.Context) {
+// @Router /manager/account/{id}/close [post]
+func CloseAccount(context *gin.Context) {
+	var input CloseAccountRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
 	id := context.Param("id")
 	ID, _ := strconv.ParseUint(id, 10, 0)
-	branches, err := models.DeleteAccountByID(uint(ID))
 
+	closure, err := models.CloseAccount(uint(ID), input.PayoutMode, input.PayoutAccountNumber, input.Reason, input.Actor)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, map[string]interface{}{"Branches": branches})
+	context.JSON(http.StatusOK, map[string]interface{}{"Closure": closure})
 }
 
 // UpdateAccount updates account information.
@@ -242,3 +362,152 @@ func UpdateAccount(context *gin.Context) {
 
 	context.JSON(http.StatusCreated, map[string]interface{}{"Account": updatedAccount})
 }
+
+// ChangeAccountStatusRequest represents the request structure for changing an account's status.
+type ChangeAccountStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active dormant debit_frozen credit_frozen total_frozen"`
+	Reason string `json:"reason" binding:"required"`
+	Actor  string `json:"actor" binding:"required"`
+}
+
+// ChangeAccountStatus changes the status of an account.
+// @Summary Change the status of an account
+// @Description Move an account to a new status if the transition is allowed
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body ChangeAccountStatusRequest true "New status"
+// @Success 200 {object} map[string]interface{} "Account status changed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/status [put]
+func ChangeAccountStatus(context *gin.Context) {
+	var input ChangeAccountStatusRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	account, err := models.ChangeAccountStatus(uint(ID), input.Status, input.Reason, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Account": account})
+}
+
+// GetAccountAuditLog retrieves the audit trail of an account.
+// @Summary Get the audit trail of an account
+// @Description Retrieve every audited change made to an account
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} map[string]interface{} "Audit trail retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/audit [get]
+func GetAccountAuditLog(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	logs, err := models.FindAllAuditLogsByEntity("account", uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Audit": logs})
+}
+
+// ReactivateAccountRequest represents the request structure for lifting the KYC block on a frozen or dormant account.
+type ReactivateAccountRequest struct {
+	Actor string `json:"actor" binding:"required"`
+}
+
+// ReactivateAccount reactivates a dormant account.
+// @Summary Reactivate a dormant account
+// @Description Return a dormant account to active once every holder has a verified KYC record (see /manager/kyc/{id}/review)
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body ReactivateAccountRequest true "Reactivation details"
+// @Success 200 {object} map[string]interface{} "Account reactivated successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/reactivate [post]
+func ReactivateAccount(context *gin.Context) {
+	var input ReactivateAccountRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	account, err := models.ReactivateAccount(uint(ID), input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Account": account})
+}
+
+// CompleteMajorityKYC lifts the debit freeze on an account whose minor holder has come of age.
+// @Summary Complete KYC after majority
+// @Description Lift the debit freeze on the account of a holder who has attained majority once every holder has a verified KYC record (see /manager/kyc/{id}/review)
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body ReactivateAccountRequest true "Release details"
+// @Success 200 {object} map[string]interface{} "Account unfrozen successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/majority-kyc [post]
+func CompleteMajorityKYC(context *gin.Context) {
+	var input ReactivateAccountRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	account, err := models.CompleteMajorityKYC(uint(ID), input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Account": account})
+}
+
+// GetDormancyReportByBranchID retrieves the dormant and unclaimed accounts of a branch.
+// @Summary Get dormant and unclaimed accounts by branch ID
+// @Description Retrieve the dormant accounts and the unclaimed-deposits register of a branch
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} map[string]interface{} "Report retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/branch/{id}/dormant [get]
+func GetDormancyReportByBranchID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	report, err := models.FindDormancyReportByBranchID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Report": report})
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateATMRequest represents the request structure for installing an ATM at a branch.
+type CreateATMRequest struct {
+	BranchID uint   `json:"branch_id" binding:"required"`
+	Location string `json:"location" binding:"required"`
+}
+
+// CassetteLoad represents the notes of one denomination loaded into an ATM.
+type CassetteLoad struct {
+	Denomination uint `json:"denomination" binding:"required"`
+	Count        uint `json:"count" binding:"required"`
+}
+
+// ReplenishATMRequest represents the request structure for replenishing an ATM.
+type ReplenishATMRequest struct {
+	Cassettes []CassetteLoad `json:"cassettes" binding:"required,dive"`
+	Actor     string         `json:"actor" binding:"required"`
+}
+
+// ReverseATMDispenseRequest represents the request structure for reversing an ATM withdrawal.
+type ReverseATMDispenseRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ATMWithdrawalRequest represents a cash withdrawal made at an ATM.
+type ATMWithdrawalRequest struct {
+	CardNumber              string `json:"card_number" binding:"required"`
+	PIN                     string `json:"pin" binding:"required"`
+	Amount                  uint   `json:"amount" binding:"required"`
+	SimulateDispenseFailure bool   `json:"simulate_dispense_failure"`
+}
+
+// CreateATM installs a new ATM at a branch.
+// @Summary Create a new ATM
+// @Description Install a new ATM at a branch with empty cassettes
+// @Tags ATMs
+// @Accept json
+// @Produce json
+// @Param body body CreateATMRequest true "ATM to be created"
+// @Success 201 {object} map[string]interface{} "ATM created successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/atm [post]
+func CreateATM(context *gin.Context) {
+	var input CreateATMRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	atm := models.ATM{
+		BranchID: input.BranchID,
+		Location: input.Location,
+	}
+
+	savedATM, err := atm.Save()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"ATM": savedATM})
+}
+
+// GetAllATMsByBranchID retrieves all ATMs of a branch.
+// @Summary Get all ATMs by branch ID
+// @Description Retrieve all ATMs of a branch along with their cassettes
+// @Tags ATMs
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} map[string]interface{} "ATMs retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/branch/{id}/atm [get]
+func GetAllATMsByBranchID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	atms, err := models.FindAllATMsByBranchID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"ATMs": atms})
+}
+
+// ReplenishATM loads cash into an ATM.
+// @Summary Replenish an ATM
+// @Description Load notes into the cassettes of an ATM
+// @Tags ATMs
+// @Accept json
+// @Produce json
+// @Param id path int true "ATM ID"
+// @Param body body ReplenishATMRequest true "Notes to be loaded"
+// @Success 200 {object} map[string]interface{} "ATM replenished successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/atm/{id}/replenish [post]
+func ReplenishATM(context *gin.Context) {
+	var input ReplenishATMRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	notes := map[uint]uint{}
+	for _, load := range input.Cassettes {
+		notes[load.Denomination] += load.Count
+	}
+
+	atm, err := models.ReplenishATM(uint(ID), notes, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"ATM": atm})
+}
+
+// ReconcileATM compares the cash dispensed by an ATM with the posted withdrawals.
+// @Summary Reconcile an ATM
+// @Description Compare the cash that left an ATM with the withdrawals and reversals posted against it
+// @Tags ATMs
+// @Produce json
+// @Param id path int true "ATM ID"
+// @Success 200 {object} map[string]interface{} "Reconciliation computed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/atm/{id}/reconcile [get]
+func ReconcileATM(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	reconciliation, err := models.ReconcileATM(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Reconciliation": reconciliation})
+}
+
+// ReverseATMDispense reverses an ATM withdrawal whose cash was not handed out.
+// @Summary Reverse an ATM withdrawal
+// @Description Return the notes to the cassettes and credit the account back
+// @Tags ATMs
+// @Accept json
+// @Produce json
+// @Param id path int true "Dispense ID"
+// @Param body body ReverseATMDispenseRequest true "Reversal reason"
+// @Success 200 {object} map[string]interface{} "Withdrawal reversed"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/atm/dispense/{id}/reverse [post]
+func ReverseATMDispense(context *gin.Context) {
+	var input ReverseATMDispenseRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	dispense, err := models.ReverseATMDispense(uint(ID), input.Reason)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Dispense": dispense})
+}
+
+// ATMWithdrawal withdraws cash at an ATM.
+// @Summary Withdraw cash at an ATM
+// @Description Verify the card and PIN, dispense notes by denomination and debit the account
+// @Tags ATMs
+// @Accept json
+// @Produce json
+// @Param id path int true "ATM ID"
+// @Param body body ATMWithdrawalRequest true "Withdrawal request"
+// @Success 200 {object} map[string]interface{} "Cash dispensed"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /terminal/atm/{id}/withdraw [post]
+func ATMWithdrawal(context *gin.Context) {
+	var input ATMWithdrawalRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	dispense, err := models.ATMWithdrawal(uint(ID), input.CardNumber, input.PIN, input.Amount, input.SimulateDispenseFailure)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Dispense": dispense})
+}
@@ -48,19 +48,28 @@ func CreateBank(context *gin.Context) {
 // @Description Retrieve all banks
 // @Tags Banks
 // @Produce json
+// @Param limit query int false "Page size, at most 500"
+// @Param cursor query string false "Cursor returned with the previous page"
+// @Param sort query string false "id or name"
+// @Param order query string false "asc or desc"
 // @Success 200 {object} map[string]interface{} "Banks retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "error": "Bad request"
 // @Router /super/bank [get]
 func GetAllBanks(context *gin.Context) {
 
-	banks,err := models.FindAllBanks()
+	opts, err := listOptions(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
+	banks, page, err := models.FindAllBanks(opts)
 	if err != nil {
 		context.JSON(http.StatusBadRequest,gin.H{"error":err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, gin.H{"Banks":banks})
+	context.JSON(http.StatusOK, gin.H{"Banks": banks, "Page": page})
 }
 
 // @Summary Get a bank by ID
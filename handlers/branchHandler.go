@@ -44,21 +44,30 @@ func CreateBranch(context *gin.Context) {
 // @Tags Branches
 // @Produce json
 // @Param id path int true "Bank ID"
-// @Success 200 {array} models.Branch "Branches retrieved successfully"
+// @Param limit query int false "Page size, at most 500"
+// @Param cursor query string false "Cursor returned with the previous page"
+// @Param sort query string false "id or address"
+// @Param order query string false "asc or desc"
+// @Success 200 {object} map[string]interface{} "Branches retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "error": "Bad request"
 // @Router /admin/branch/{id} [get]
 func GetAllBranchesByBankID(context *gin.Context) {
 	id := context.Param("id")
 	ID,_ := strconv.ParseUint(id,10,0)
 
-	branches,err := models.FindAllBranchesByBankID(uint(ID))
+	opts, err := listOptions(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
+	branches, page, err := models.FindAllBranchesByBankID(uint(ID), opts)
 	if err != nil {
 		context.JSON(http.StatusBadRequest,gin.H{"error":err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, gin.H{"Branches":branches})
+	context.JSON(http.StatusOK, gin.H{"Branches": branches, "Page": page})
 }
 
 // GetBranchByID retrieves a branch by its ID.
@@ -151,3 +160,25 @@ func UpdateBranch(context *gin.Context) {
 	context.JSON(http.StatusCreated, gin.H{"Branch":updatedBranch})
 }
 
+
+// GetAllNotificationsByBranchID retrieves the notifications sent to a branch.
+// @Summary Get all notifications by branch ID
+// @Description Retrieve the notifications sent to a branch, newest first
+// @Tags Branches
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} map[string]interface{} "Notifications retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/branch/{id}/notification [get]
+func GetAllNotificationsByBranchID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	notifications, err := models.FindAllNotificationsByBranchID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"Notifications": notifications})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BusinessDayRequest represents the request structure for running end of day or opening the next business day.
+type BusinessDayRequest struct {
+	Actor string `json:"actor" binding:"required"`
+}
+
+// GetBusinessDayByBankID retrieves the current business day of a bank.
+// @Summary Get the business day of a bank
+// @Description Retrieve the business date of a bank and whether it is open, in end of day or closed
+// @Tags Business Day
+// @Produce json
+// @Param id path int true "Bank ID"
+// @Success 200 {object} map[string]interface{} "Business day retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/bank/{id}/business-day [get]
+func GetBusinessDayByBankID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	day, err := models.FindBusinessDayByBankID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"BusinessDay": day})
+}
+
+// RunEOD runs the end of day of a bank.
+// @Summary Run end of day
+// @Description Close the business day of a bank: accrue interest, run standing instructions and dormancy, and generate the day-end report. A failed run resumes from the step that failed.
+// @Tags Business Day
+// @Accept json
+// @Produce json
+// @Param id path int true "Bank ID"
+// @Param body body BusinessDayRequest true "Actor"
+// @Success 200 {object} map[string]interface{} "End of day completed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Failure 500 {object} map[string]interface{} "error: A step failed"
+// @Router /super/bank/{id}/eod [post]
+func RunEOD(context *gin.Context) {
+	var input BusinessDayRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	run, err := models.RunEOD(uint(ID), input.Actor)
+	if err != nil && run != nil {
+		context.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error(), "EODRun": run})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"EODRun": run})
+}
+
+// StartBusinessDay opens the next business day of a bank.
+// @Summary Start the next business day
+// @Description Open the day after a business day closed by end of day
+// @Tags Business Day
+// @Accept json
+// @Produce json
+// @Param id path int true "Bank ID"
+// @Param body body BusinessDayRequest true "Actor"
+// @Success 200 {object} map[string]interface{} "Business day started successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/bank/{id}/bod [post]
+func StartBusinessDay(context *gin.Context) {
+	var input BusinessDayRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	day, err := models.StartBusinessDay(uint(ID), input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"BusinessDay": day})
+}
+
+// GetAllEODRunsByBankID retrieves the end of day runs of a bank.
+// @Summary Get end of day runs by bank ID
+// @Description Retrieve the end of day runs of a bank, latest first, with their completed steps
+// @Tags Business Day
+// @Produce json
+// @Param id path int true "Bank ID"
+// @Success 200 {object} map[string]interface{} "End of day runs retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/bank/{id}/eod [get]
+func GetAllEODRunsByBankID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	runs, err := models.FindAllEODRunsByBankID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"EODRun": runs})
+}
+
+// GetDayEndReport retrieves the day-end report of a bank.
+// @Summary Get a day-end report
+// @Description Retrieve the report generated by end of day for a business date
+// @Tags Business Day
+// @Produce json
+// @Param id path int true "Bank ID"
+// @Param date query string true "Business date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Day-end report retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/bank/{id}/eod/report [get]
+func GetDayEndReport(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	date, err := time.Parse(statementDateLayout, context.Query("date"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "date must be a date in YYYY-MM-DD format"})
+		return
+	}
+
+	report, err := models.FindDayEndReport(uint(ID), date)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"DayEndReport": report})
+}
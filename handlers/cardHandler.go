@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IssueCardRequest represents the request structure for issuing a debit card.
+type IssueCardRequest struct {
+	CustomerID uint    `json:"customer_id" binding:"required"`
+	PIN        string  `json:"pin" binding:"required"`
+	ATMLimit   float64 `json:"atm_limit"`
+	POSLimit   float64 `json:"pos_limit"`
+}
+
+// AuthorizeCardRequest represents a card-present authorization sent by a terminal.
+type AuthorizeCardRequest struct {
+	CardNumber string  `json:"card_number" binding:"required"`
+	PIN        string  `json:"pin" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required"`
+	Channel    string  `json:"channel" binding:"required,oneof=ATM POS"`
+	TerminalID string  `json:"terminal_id" binding:"required"`
+	Capture    bool    `json:"capture"`
+}
+
+// BlockCardRequest represents the request structure for blocking a card.
+type BlockCardRequest struct {
+	CardNumber string `json:"card_number" binding:"required"`
+	HotList    bool   `json:"hot_list"`
+}
+
+// UnblockCardRequest represents the request structure for unblocking a card.
+type UnblockCardRequest struct {
+	CardNumber string `json:"card_number" binding:"required"`
+	PIN        string `json:"pin" binding:"required"`
+}
+
+// ReleaseCardBlockRequest represents the request structure for staff unblocking a card.
+type ReleaseCardBlockRequest struct {
+	CardNumber string `json:"card_number" binding:"required"`
+	Actor      string `json:"actor" binding:"required"`
+}
+
+// ChangeCardPINRequest represents the request structure for changing a card's PIN.
+type ChangeCardPINRequest struct {
+	CardNumber string `json:"card_number" binding:"required"`
+	OldPIN     string `json:"old_pin" binding:"required"`
+	NewPIN     string `json:"new_pin" binding:"required"`
+}
+
+// IssueCard issues a debit card on an account.
+// @Summary Issue a debit card
+// @Description Issue a debit card linked to an account to one of its holders, or a minor's guardian, with a PIN and ATM and POS limits
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body IssueCardRequest true "Card to be issued"
+// @Success 201 {object} map[string]interface{} "Card issued successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/card [post]
+func IssueCard(context *gin.Context) {
+	var input IssueCardRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	card, err := models.IssueCard(uint(ID), input.CustomerID, input.PIN, input.ATMLimit, input.POSLimit)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"Card": card})
+}
+
+// GetAllCardsByAccountNumber retrieves all cards linked to an account.
+// @Summary Get all cards by account number
+// @Description Retrieve all debit cards linked to an account with masked card numbers
+// @Tags Cards
+// @Produce json
+// @Param number path string true "Account number"
+// @Success 200 {object} map[string]interface{} "Cards retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/account/{number}/card [get]
+func GetAllCardsByAccountNumber(context *gin.Context) {
+	number, err := uuid.Parse(context.Param("number"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	cards, err := models.FindAllCardsByAccountNumber(number)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Cards": cards})
+}
+
+// AuthorizeCard authorizes a card-present payment from a terminal.
+// @Summary Authorize a card payment
+// @Description Verify the PIN and limits of a card and either debit the account or place a hold
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param body body AuthorizeCardRequest true "Authorization request"
+// @Success 200 {object} map[string]interface{} "Authorization approved"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /terminal/card/authorize [post]
+func AuthorizeCard(context *gin.Context) {
+	var input AuthorizeCardRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	authorization, err := models.AuthorizeCard(input.CardNumber, input.PIN, input.Amount, input.Channel, input.TerminalID, input.Capture)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Authorization": authorization})
+}
+
+// CaptureCardHold completes a held card authorization.
+// @Summary Capture a card authorization
+// @Description Release the hold placed by a card authorization and debit the account
+// @Tags Cards
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} map[string]interface{} "Authorization captured"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /terminal/card/capture/{id} [post]
+func CaptureCardHold(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	transaction, err := models.CaptureCardHold(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Transaction": transaction})
+}
+
+// BlockCard blocks or hot-lists a card.
+// @Summary Block a card
+// @Description Block a card temporarily, or hot-list it if it was lost or stolen
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param body body BlockCardRequest true "Card to be blocked"
+// @Success 200 {object} map[string]interface{} "Card blocked"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/card/block [post]
+func BlockCard(context *gin.Context) {
+	var input BlockCardRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	card, err := models.BlockCard(input.CardNumber, input.HotList)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Card": card})
+}
+
+// UnblockCard unblocks a card blocked by its holder.
+// @Summary Unblock a card
+// @Description Reactivate a card the holder blocked, given its PIN. Wrong PINs count towards the PIN lockout. Cards locked after too many wrong PINs are unblocked by the branch, and hot-listed cards cannot be unblocked.
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param body body UnblockCardRequest true "Card to be unblocked"
+// @Success 200 {object} map[string]interface{} "Card unblocked"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/card/unblock [post]
+func UnblockCard(context *gin.Context) {
+	var input UnblockCardRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	card, err := models.UnblockCard(input.CardNumber, input.PIN)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Card": card})
+}
+
+// ReleaseCardBlock lets the branch unblock a card.
+// @Summary Unblock a card at the branch
+// @Description Reactivate a blocked card, including one locked after too many wrong PINs, and reset its wrong PIN count. Hot-listed cards cannot be unblocked.
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param body body ReleaseCardBlockRequest true "Card to be unblocked"
+// @Success 200 {object} map[string]interface{} "Card unblocked"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/card/unblock [post]
+func ReleaseCardBlock(context *gin.Context) {
+	var input ReleaseCardBlockRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	card, err := models.ReleaseCardBlock(input.CardNumber, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Card": card})
+}
+
+// ChangeCardPIN changes the PIN of a card.
+// @Summary Change a card's PIN
+// @Description Change the PIN of a card after verifying the current one
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param body body ChangeCardPINRequest true "Old and new PIN"
+// @Success 200 {object} map[string]interface{} "Message: PIN changed"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/card/pin [put]
+func ChangeCardPIN(context *gin.Context) {
+	var input ChangeCardPINRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	err := models.ChangeCardPIN(input.CardNumber, input.OldPIN, input.NewPIN)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Message": "PIN changed"})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IssueChequeBookRequest represents the request structure for issuing a cheque book.
+type IssueChequeBookRequest struct {
+	CustomerID uint `json:"customer_id" binding:"required"`
+	Leaves     uint `json:"leaves" binding:"required,min=1,max=100"`
+}
+
+// ClearChequeRequest represents the request structure for presenting a cheque for clearing.
+type ClearChequeRequest struct {
+	AccountNumber      uuid.UUID `json:"account_number" binding:"required"`
+	ChequeNumber       uint      `json:"cheque_number" binding:"required"`
+	Amount             float64   `json:"amount" binding:"required"`
+	Payee              string    `json:"payee" binding:"required"`
+	ChequeDate         time.Time `json:"cheque_date" binding:"required"`
+	PayeeAccountNumber uuid.UUID `json:"payee_account_number"`
+}
+
+// StopChequeRequest represents the request structure for a stop-payment instruction.
+type StopChequeRequest struct {
+	AccountNumber uuid.UUID `json:"account_number" binding:"required"`
+	ChequeNumber  uint      `json:"cheque_number" binding:"required"`
+	Reason        string    `json:"reason" binding:"required"`
+}
+
+// IssueChequeBook issues a cheque book on an account.
+// @Summary Issue a cheque book
+// @Description Issue a cheque book with a range of leaf numbers on an account to one of its holders, or a minor's guardian
+// @Tags Cheques
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body IssueChequeBookRequest true "Holder and number of leaves"
+// @Success 201 {object} map[string]interface{} "Cheque book issued successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/chequebook [post]
+func IssueChequeBook(context *gin.Context) {
+	var input IssueChequeBookRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	book, err := models.IssueChequeBook(uint(ID), input.CustomerID, input.Leaves)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"ChequeBook": book})
+}
+
+// GetAllChequeBooksByAccountID retrieves all cheque books issued on an account.
+// @Summary Get all cheque books by account ID
+// @Description Retrieve all cheque books issued on an account along with their leaves
+// @Tags Cheques
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} map[string]interface{} "Cheque books retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/chequebook [get]
+func GetAllChequeBooksByAccountID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	books, err := models.FindAllChequeBooksByAccountID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"ChequeBooks": books})
+}
+
+// ClearCheque presents a cheque for clearing.
+// @Summary Clear a cheque
+// @Description Present a cheque against the drawer's account and either pay it or record it as bounced
+// @Tags Cheques
+// @Accept json
+// @Produce json
+// @Param body body ClearChequeRequest true "Cheque to be cleared"
+// @Success 200 {object} map[string]interface{} "Cheque paid"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Failure 422 {object} map[string]interface{} "error: Cheque bounced"
+// @Router /manager/cheque/clear [post]
+func ClearCheque(context *gin.Context) {
+	var input ClearChequeRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	cheque, err := models.ClearCheque(input.AccountNumber, input.ChequeNumber, input.Amount, input.Payee, input.ChequeDate, input.PayeeAccountNumber)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	if cheque.Status == models.CHEQUE_BOUNCED {
+		context.JSON(http.StatusUnprocessableEntity, map[string]interface{}{"error": cheque.BounceReason, "Cheque": cheque})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Cheque": cheque})
+}
+
+// StopCheque places a stop-payment instruction on a cheque.
+// @Summary Stop payment of a cheque
+// @Description Place a stop-payment instruction on an unused cheque leaf
+// @Tags Cheques
+// @Accept json
+// @Produce json
+// @Param body body StopChequeRequest true "Stop-payment instruction"
+// @Success 200 {object} map[string]interface{} "Stop-payment placed"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/account/cheque/stop [post]
+func StopCheque(context *gin.Context) {
+	var input StopChequeRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	cheque, err := models.StopCheque(input.AccountNumber, input.ChequeNumber, input.Reason)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Cheque": cheque})
+}
+
+// GetAllChequesByAccountNumber retrieves all cheque leaves of an account.
+// @Summary Get all cheques by account number
+// @Description Retrieve every cheque leaf issued on an account with its status
+// @Tags Cheques
+// @Produce json
+// @Param number path string true "Account number"
+// @Success 200 {object} map[string]interface{} "Cheques retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/account/{number}/cheque [get]
+func GetAllChequesByAccountNumber(context *gin.Context) {
+	number, err := uuid.Parse(context.Param("number"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	cheques, err := models.FindAllChequesByAccountNumber(number)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Cheques": cheques})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReportDeathRequest represents the request structure for reporting the death of a customer.
+type ReportDeathRequest struct {
+	DateOfDeath time.Time `json:"date_of_death" binding:"required"`
+	Actor       string    `json:"actor" binding:"required"`
+}
+
+// AddClaimDocumentRequest represents a document submitted for a deceased claim.
+type AddClaimDocumentRequest struct {
+	DocumentType string `json:"document_type" binding:"required"`
+	Reference    string `json:"reference" binding:"required"`
+}
+
+// ClaimPayoutRequest represents how the share of one nominee is paid out.
+type ClaimPayoutRequest struct {
+	NomineeID           uint      `json:"nominee_id" binding:"required"`
+	PayoutMode          string    `json:"payout_mode" binding:"required,oneof=transfer cash"`
+	PayoutAccountNumber uuid.UUID `json:"payout_account_number"`
+}
+
+// ApproveDeceasedClaimRequest represents the request structure for approving a deceased claim.
+type ApproveDeceasedClaimRequest struct {
+	Payouts []ClaimPayoutRequest `json:"payouts" binding:"dive"`
+	Actor   string               `json:"actor" binding:"required"`
+}
+
+// ReportDeath marks a customer deceased and opens a claim.
+// @Summary Report the death of a customer
+// @Description Mark a customer deceased and open a claim. Sole accounts are frozen for debits, jointly operated accounts are frozen and survivorship accounts pass to the other holders.
+// @Tags Claims
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param body body ReportDeathRequest true "Date of death"
+// @Success 201 {object} map[string]interface{} "Claim opened successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/customer/{id}/deceased [post]
+func ReportDeath(context *gin.Context) {
+	var input ReportDeathRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	claim, err := models.ReportDeath(uint(ID), input.DateOfDeath, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"Claim": claim})
+}
+
+// GetDeceasedClaimByID retrieves a deceased claim.
+// @Summary Get a deceased claim by ID
+// @Description Retrieve a deceased claim with its documents and payouts
+// @Tags Claims
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Success 200 {object} map[string]interface{} "Claim retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/claim/{id} [get]
+func GetDeceasedClaimByID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	claim, err := models.FindDeceasedClaimByID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Claim": claim})
+}
+
+// AddClaimDocument records a document submitted for a deceased claim.
+// @Summary Add a claim document
+// @Description Record a document submitted for a deceased claim, such as the death certificate or claim form
+// @Tags Claims
+// @Accept json
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param body body AddClaimDocumentRequest true "Claim document"
+// @Success 201 {object} map[string]interface{} "Document added successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/claim/{id}/document [post]
+func AddClaimDocument(context *gin.Context) {
+	var input AddClaimDocumentRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	document, err := models.AddClaimDocument(uint(ID), input.DocumentType, input.Reference)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"Document": document})
+}
+
+// ApproveDeceasedClaim approves and settles a deceased claim.
+// @Summary Approve a deceased claim
+// @Description Pay the balance of the deceased's sole accounts out to their nominees by share, close those accounts and release frozen joint accounts. Each nominee entry is paid by transfer to an account or in cash; nominees without a payout are paid in cash. Accounts that cannot be settled are listed on the claim, which stays partially settled and can be approved again.
+// @Tags Claims
+// @Accept json
+// @Produce json
+// @Param id path int true "Claim ID"
+// @Param body body ApproveDeceasedClaimRequest true "Approving manager"
+// @Success 200 {object} map[string]interface{} "Claim settled"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/claim/{id}/approve [post]
+func ApproveDeceasedClaim(context *gin.Context) {
+	var input ApproveDeceasedClaimRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	payouts := make([]models.ClaimPayoutInstruction, 0, len(input.Payouts))
+	for _, payout := range input.Payouts {
+		payouts = append(payouts, models.ClaimPayoutInstruction{
+			NomineeID:           payout.NomineeID,
+			PayoutMode:          payout.PayoutMode,
+			PayoutAccountNumber: payout.PayoutAccountNumber,
+		})
+	}
+
+	claim, err := models.ApproveDeceasedClaim(uint(ID), payouts, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Claim": claim})
+}
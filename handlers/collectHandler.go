@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RaiseCollectRequestRequest represents the request structure for asking another customer to pay.
+type RaiseCollectRequestRequest struct {
+	PayeeAccountNumber uuid.UUID `json:"payee_account_number" binding:"required"`
+	PayerAccountNumber uuid.UUID `json:"payer_account_number" binding:"required_without=PayerVPA"`
+	PayerVPA           string    `json:"payer_vpa" binding:"required_without=PayerAccountNumber"`
+	Amount             float64   `json:"amount" binding:"required"`
+	Note               string    `json:"note"`
+	Expiry             time.Time `json:"expiry"`
+}
+
+// RespondCollectRequestRequest represents the payer's response to a collect request.
+type RespondCollectRequestRequest struct {
+	PayerAccountNumber uuid.UUID `json:"payer_account_number" binding:"required"`
+}
+
+// ApproveCollectRequestRequest represents the payer's approval of a collect request.
+type ApproveCollectRequestRequest struct {
+	PayerAccountNumber uuid.UUID `json:"payer_account_number" binding:"required"`
+	CustomerID         uint      `json:"customer_id" binding:"required"`
+}
+
+// RaiseCollectRequest asks a payer to pay into the requester's account.
+// @Summary Raise a collect request
+// @Description Ask the holder of an account number or VPA to pay an amount before the expiry
+// @Tags Collect Requests
+// @Accept json
+// @Produce json
+// @Param body body RaiseCollectRequestRequest true "Collect request to be raised"
+// @Success 201 {object} map[string]interface{} "Collect request raised successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/collect [post]
+func RaiseCollectRequest(context *gin.Context) {
+	var input RaiseCollectRequestRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	request, err := models.RaiseCollectRequest(input.PayeeAccountNumber, input.PayerAccountNumber, input.PayerVPA, input.Amount, input.Note, input.Expiry)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"CollectRequest": request})
+}
+
+// GetAllCollectRequestsByAccountNumber lists the collect requests of an account.
+// @Summary Get all collect requests by account number
+// @Description Retrieve the collect requests an account has to pay or has raised, optionally filtered by status
+// @Tags Collect Requests
+// @Produce json
+// @Param number path string true "Account number"
+// @Param role query string true "payer or payee"
+// @Param status query string false "pending, approved, declined or expired"
+// @Success 200 {object} map[string]interface{} "Collect requests retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/account/{number}/collect [get]
+func GetAllCollectRequestsByAccountNumber(context *gin.Context) {
+	number, err := uuid.Parse(context.Param("number"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	requests, err := models.FindAllCollectRequestsByAccountNumber(number, context.Query("role"), context.Query("status"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CollectRequests": requests})
+}
+
+// ApproveCollectRequest pays a pending collect request.
+// @Summary Approve a collect request
+// @Description Transfer the requested amount from the payer's account to the payee's account
+// @Tags Collect Requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Collect request ID"
+// @Param body body ApproveCollectRequestRequest true "Payer account and approving holder"
+// @Success 200 {object} map[string]interface{} "Collect request approved"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/collect/{id}/approve [post]
+func ApproveCollectRequest(context *gin.Context) {
+	var input ApproveCollectRequestRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	request, err := models.ApproveCollectRequest(uint(ID), input.PayerAccountNumber, input.CustomerID)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CollectRequest": request})
+}
+
+// DeclineCollectRequest declines a pending collect request.
+// @Summary Decline a collect request
+// @Description Decline a pending collect request without paying it
+// @Tags Collect Requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Collect request ID"
+// @Param body body RespondCollectRequestRequest true "Payer account"
+// @Success 200 {object} map[string]interface{} "Collect request declined"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/collect/{id}/decline [post]
+func DeclineCollectRequest(context *gin.Context) {
+	var input RespondCollectRequestRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	request, err := models.DeclineCollectRequest(uint(ID), input.PayerAccountNumber)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CollectRequest": request})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SaveCTRRuleRequest represents the request structure for creating or updating a cash transaction reporting rule.
+type SaveCTRRuleRequest struct {
+	ID        uint     `json:"id"`
+	Name      string   `json:"name" binding:"required"`
+	Kind      string   `json:"kind" binding:"required,oneof=single daily"`
+	Threshold float64  `json:"threshold" binding:"required"`
+	Modes     []string `json:"modes"`
+	Active    bool     `json:"active"`
+}
+
+// ReviewCTRFlagRequest represents the request structure for reviewing a flagged cash transaction.
+type ReviewCTRFlagRequest struct {
+	Status string `json:"status" binding:"required,oneof=reportable dismissed"`
+	Note   string `json:"note"`
+	Actor  string `json:"actor" binding:"required"`
+}
+
+// SaveCTRRule creates or updates a cash transaction reporting rule.
+// @Summary Save a cash transaction reporting rule
+// @Description Flag cash transactions of a customer at or above a threshold, either singly or added up per day across all their accounts. Without an id a new rule is created.
+// @Tags Cash Transaction Reports
+// @Accept json
+// @Produce json
+// @Param body body SaveCTRRuleRequest true "Rule"
+// @Success 200 {object} map[string]interface{} "Rule saved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/ctr/rule [put]
+func SaveCTRRule(context *gin.Context) {
+	var input SaveCTRRuleRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rule, err := models.SaveCTRRule(&models.CTRRule{
+		ID:        input.ID,
+		Name:      input.Name,
+		Kind:      input.Kind,
+		Threshold: input.Threshold,
+		Modes:     input.Modes,
+		Active:    input.Active,
+	})
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CTRRule": rule})
+}
+
+// GetAllCTRRules retrieves the cash transaction reporting rules.
+// @Summary Get all cash transaction reporting rules
+// @Description Retrieve all cash transaction reporting rules
+// @Tags Cash Transaction Reports
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Rules retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/ctr/rule [get]
+func GetAllCTRRules(context *gin.Context) {
+	rules, err := models.FindAllCTRRules()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CTRRule": rules})
+}
+
+// GetAllCTRFlags retrieves the flagged cash transactions of a month.
+// @Summary Get flagged cash transactions
+// @Description Retrieve the items flagged for cash transaction reporting in a month
+// @Tags Cash Transaction Reports
+// @Produce json
+// @Param month query string true "Month (YYYY-MM)"
+// @Param status query string false "pending, reportable, dismissed or filed"
+// @Success 200 {object} map[string]interface{} "Flagged items retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/ctr [get]
+func GetAllCTRFlags(context *gin.Context) {
+	flags, err := models.FindAllCTRFlags(context.Query("month"), context.Query("status"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CTRFlag": flags})
+}
+
+// ReviewCTRFlag records the review of a flagged cash transaction.
+// @Summary Review a flagged cash transaction
+// @Description Mark a flagged item reportable, to include it in the monthly report, or dismissed
+// @Tags Cash Transaction Reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Flagged item ID"
+// @Param body body ReviewCTRFlagRequest true "Review"
+// @Success 200 {object} map[string]interface{} "Flagged item reviewed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/ctr/{id}/review [post]
+func ReviewCTRFlag(context *gin.Context) {
+	var input ReviewCTRFlagRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	flag, err := models.ReviewCTRFlag(uint(ID), input.Status, input.Note, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CTRFlag": flag})
+}
+
+// GenerateCTRReport generates the cash transaction report of a month.
+// @Summary Generate the monthly cash transaction report
+// @Description Scan the month, then file every reportable item and download the report in its fixed-width format. All flagged items must have been reviewed.
+// @Tags Cash Transaction Reports
+// @Produce plain
+// @Param month query string true "Month (YYYY-MM)"
+// @Success 200 {string} string "Report file"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/ctr/report [post]
+func GenerateCTRReport(context *gin.Context) {
+	month := context.Query("month")
+
+	report, err := models.GenerateCTRReport(month)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.Header("Content-Disposition", "attachment; filename=ctr-"+month+".txt")
+	context.Data(http.StatusOK, "text/plain", report)
+}
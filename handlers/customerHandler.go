@@ -22,17 +22,40 @@ type CreateCustomerRequest struct {
 	Address      string  `json:"address" binding:"required"`
 	Balance      float64 `json:"balance" binding:"required"`
 	AccountType  string  `json:"account_type" binding:"required"`
+	GuardianID   uint    `json:"guardian_id"`
+	// OverrideBy and OverrideJustification create the customer in spite of
+	// possible duplicates.
+	OverrideBy            string `json:"override_by"`
+	OverrideJustification string `json:"override_justification"`
+}
+
+// MergeCustomersRequest represents the request structure for merging a duplicate customer into another.
+type MergeCustomersRequest struct {
+	MergedCustomerID uint `json:"merged_customer_id" binding:"required"`
+	// Choices picks "survivor" or "merged" for each field whose values differ.
+	Choices map[string]string `json:"choices"`
+	Actor   string            `json:"actor" binding:"required"`
+	Preview bool              `json:"preview"`
+}
+
+// FindDuplicateCustomersRequest represents the request structure for checking a prospective customer against existing ones.
+type FindDuplicateCustomersRequest struct {
+	BranchID uint   `json:"branch_id" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	DOB      string `json:"dob" binding:"required"`
+	Phone    uint   `json:"phone" binding:"required"`
 }
 
 // CreateCustomer creates a new customer and associated account.
 // @Summary Create a new customer and account
-// @Description Create a new customer and associated account
+// @Description Create a new customer and associated account. A minor's account needs an adult guardian who operates it. The account is frozen for debits until the customer's KYC is verified. The PAN must be valid and unique in the bank, and customers who look like existing ones are refused unless staff override with a justification.
 // @Tags Customers
 // @Accept json
 // @Produce json
 // @Param body body CreateCustomerRequest true "Customer object to be created"
 // @Success 201 {object} map[string]interface{} "Customer created successfully"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Failure 409 {object} map[string]interface{} "error: Possible duplicates, with the candidates"
 // @Router /manager/customer [post]
 func CreateCustomer(context *gin.Context) {
 	var input CreateCustomerRequest
@@ -52,6 +75,37 @@ func CreateCustomer(context *gin.Context) {
 		Address:  input.Address,
 	}
 
+	if err := models.ValidatePAN(customer.PAN, customer.Name); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	candidates, err := models.FindDuplicateCustomers(&customer)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	override := input.OverrideBy != "" && input.OverrideJustification != ""
+	if len(candidates) > 0 && !override {
+		context.JSON(http.StatusConflict, map[string]interface{}{
+			"error":      "possible duplicate customers found; review them or override with a justification",
+			"Candidates": candidates,
+		})
+		return
+	}
+
+	minor, err := customer.IsMinor()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if minor {
+		if err := models.CheckGuardian(&customer, input.GuardianID); err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+
 	account := &models.Account{
 		BranchID:    input.BranchID,
 		Balance:     input.Balance,
@@ -64,6 +118,12 @@ func CreateCustomer(context *gin.Context) {
 		return
 	}
 
+	account.Status, account.StatusReason, err = models.OpeningStatus(savedCustomer.ID)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"err": err.Error()})
+		return
+	}
+
 	savedAccount, err := account.Save()
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"err": err.Error()})
@@ -72,9 +132,18 @@ func CreateCustomer(context *gin.Context) {
 
 	savedCustomer.Account = append(savedCustomer.Account, savedAccount)
 
+	if len(candidates) > 0 {
+		err = models.RecordDuplicateOverride(savedCustomer.ID, candidates, input.OverrideBy, input.OverrideJustification)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"err": err.Error()})
+			return
+		}
+	}
+
 	mapping := models.CustomerToAccount{
 		CustomerID: savedCustomer.ID,
 		AccountID:  savedAccount.ID,
+		Role:       models.ROLE_PRIMARY,
 	}
 
 	err = mapping.Save()
@@ -83,15 +152,99 @@ func CreateCustomer(context *gin.Context) {
 		return
 	}
 
+	if minor {
+		mapping := models.CustomerToAccount{
+			CustomerID: input.GuardianID,
+			AccountID:  savedAccount.ID,
+			Role:       models.ROLE_GUARDIAN,
+		}
+		err = mapping.Save()
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"err": err.Error()})
+			return
+		}
+	}
+
 	context.JSON(http.StatusCreated, map[string]interface{}{"Customer": savedCustomer})
 }
 
+// FindDuplicateCustomers checks a prospective customer against existing ones.
+// @Summary Find possible duplicate customers
+// @Description Fuzzy-match the name, date of birth and phone number of a prospective customer against the living customers of the branch's bank and return the likely matches, best first
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param body body FindDuplicateCustomersRequest true "Prospective customer"
+// @Success 200 {object} map[string]interface{} "Candidates retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/customer/duplicates [post]
+func FindDuplicateCustomers(context *gin.Context) {
+	var input FindDuplicateCustomersRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	candidates, err := models.FindDuplicateCustomers(&models.Customer{
+		BranchID: input.BranchID,
+		Name:     input.Name,
+		DOB:      input.DOB,
+		Phone:    input.Phone,
+	})
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Candidates": candidates})
+}
+
+// MergeCustomers merges a duplicate customer into another.
+// @Summary Merge a duplicate customer
+// @Description Merge the customer merged_customer_id into the customer in the path. Account links, nominations, KYC documents, VPAs and tax records move to the survivor; fields that differ (name, pan, dob, phone, address, branch_id) take the value chosen for them; the merged customer is kept as a tombstone pointing at the survivor. With preview set, the merge is worked out and reported without being saved.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path int true "Surviving customer ID"
+// @Param body body MergeCustomersRequest true "Merge"
+// @Success 200 {object} map[string]interface{} "Customers merged successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/customer/{id}/merge [post]
+func MergeCustomers(context *gin.Context) {
+	var input MergeCustomersRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	merge, err := models.MergeCustomers(uint(ID), input.MergedCustomerID, input.Choices, input.Actor, input.Preview)
+	if err != nil && merge != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error(), "CustomerMerge": merge})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"CustomerMerge": merge})
+}
+
 // GetAllCustomersByBranchID retrieves all customers by branch ID.
 // @Summary Get all customers by branch ID
 // @Description Retrieve all customers by branch ID
 // @Tags Customers
 // @Produce json
 // @Param id path int true "Branch ID"
+// @Param limit query int false "Page size, at most 500"
+// @Param cursor query string false "Cursor returned with the previous page"
+// @Param sort query string false "id or name"
+// @Param order query string false "asc or desc"
 // @Success 200 {object} map[string]interface{} "Customer retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
 // @Router /manager/customer/{id} [get]
@@ -99,13 +252,19 @@ func GetAllCustomersByBranchID(context *gin.Context) {
 	id := context.Param("id")
 	ID, _ := strconv.ParseUint(id, 10, 0)
 
-	customers, err := models.FindAllCustomersByBranchID(uint(ID))
+	opts, err := listOptions(context)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, map[string]interface{}{"Customer": customers})
+	customers, page, err := models.FindAllCustomersByBranchID(uint(ID), opts)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Customer": customers, "Page": page})
 }
 
 // GetCustomerByID retrieves a customer by their ID.
@@ -189,6 +348,11 @@ func UpdateCustomer(context *gin.Context) {
 		return
 	}
 
+	if err := models.ValidatePAN(input.PAN, input.Name); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
 	updatedCustomer, err := input.Update()
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
@@ -200,21 +364,25 @@ func UpdateCustomer(context *gin.Context) {
 
 // GetAllNomineesByAccountNumber retrieves all nominees by account number.
 // @Summary Get all nominees by account number
-// @Description Retrieve all nominees by account number
-// @Tags Customers
+// @Description Retrieve the nominees of an account with their relationship, date of birth and share
+// @Tags Nominees
 // @Produce json
 // @Param number path string true "Account number"
 // @Success 200 {object} map[string]interface{} "Nominees retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
 // @Router /customer/account/{number}/nominee [get]
 func GetAllNomineesByAccountNumber(context *gin.Context) {
-	number := uuid.MustParse(context.Param("number"))
+	number, err := uuid.Parse(context.Param("number"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
 
-	customers, err := models.FindAllCustomersByAccountNumber(number)
+	nominees, err := models.FindAllNomineesByAccountNumber(number)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, map[string]interface{}{"Nominees": customers})
+	context.JSON(http.StatusOK, map[string]interface{}{"Nominees": nominees})
 }
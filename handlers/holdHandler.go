@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlaceHoldRequest represents the request structure for placing a hold on an account.
+type PlaceHoldRequest struct {
+	AccountID       uint      `json:"account_id" binding:"required"`
+	Amount          float64   `json:"amount" binding:"required"`
+	Reason          string    `json:"reason" binding:"required"`
+	SourceReference string    `json:"source_reference"`
+	Expiry          time.Time `json:"expiry"`
+}
+
+// PlaceHold places a hold (lien) on an account.
+// @Summary Place a hold on an account
+// @Description Block funds on an account without moving them
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param body body PlaceHoldRequest true "Hold to be placed"
+// @Success 201 {object} map[string]interface{} "Hold placed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/hold [post]
+func PlaceHold(context *gin.Context) {
+	var input PlaceHoldRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	hold := models.Hold{
+		AccountID:       input.AccountID,
+		Amount:          input.Amount,
+		Reason:          input.Reason,
+		SourceReference: input.SourceReference,
+		Expiry:          input.Expiry,
+	}
+
+	savedHold, err := hold.Save()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"Hold": savedHold})
+}
+
+// GetAllHoldsByAccountID retrieves all holds on an account.
+// @Summary Get all holds on an account
+// @Description Retrieve all holds on an account along with its available balance
+// @Tags Holds
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} map[string]interface{} "Holds retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/hold [get]
+func GetAllHoldsByAccountID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	account, err := models.FindAccountByID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	holds, err := models.FindAllHoldsByAccountID(account.ID)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	available, err := account.AvailableBalance()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Holds": holds, "AvailableBalance": available})
+}
+
+// ReleaseHoldByID releases an active hold.
+// @Summary Release a hold
+// @Description Release an active hold so its amount becomes available again
+// @Tags Holds
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} map[string]interface{} "Hold released successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/hold/{id} [delete]
+func ReleaseHoldByID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	hold, err := models.ReleaseHoldByID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Hold": hold})
+}
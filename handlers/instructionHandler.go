@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetOperatingModeRequest represents the request structure for changing how a joint account is operated.
+type SetOperatingModeRequest struct {
+	Mode      string  `json:"mode" binding:"required,oneof=either_or_survivor former_or_survivor jointly"`
+	Threshold float64 `json:"threshold" binding:"min=0"`
+	Actor     string  `json:"actor" binding:"required"`
+}
+
+// RespondInstructionRequest represents a holder's response to a pending instruction.
+type RespondInstructionRequest struct {
+	CustomerID uint `json:"customer_id" binding:"required"`
+}
+
+// SetOperatingMode changes the mode of operation of an account.
+// @Summary Set an account's operating mode
+// @Description Set the mode of operation of a joint account. In jointly mode, debits above the threshold need approval from the other holders.
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body SetOperatingModeRequest true "Operating mode"
+// @Success 200 {object} map[string]interface{} "Operating mode changed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/mode [put]
+func SetOperatingMode(context *gin.Context) {
+	var input SetOperatingModeRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	account, err := models.SetOperatingMode(uint(ID), input.Mode, input.Threshold, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Account": account})
+}
+
+// GetAllPendingInstructionsByAccountNumber lists the instructions raised on a jointly operated account.
+// @Summary Get all instructions by account number
+// @Description Retrieve the debit instructions raised on an account along with their approvals
+// @Tags Instructions
+// @Produce json
+// @Param number path string true "Account number"
+// @Success 200 {object} map[string]interface{} "Instructions retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/account/{number}/instruction [get]
+func GetAllPendingInstructionsByAccountNumber(context *gin.Context) {
+	number, err := uuid.Parse(context.Param("number"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	instructions, err := models.FindAllPendingInstructionsByAccountNumber(number)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Instructions": instructions})
+}
+
+// ApproveInstruction approves a pending instruction on behalf of a holder.
+// @Summary Approve an instruction
+// @Description Approve a pending debit instruction. It is executed once every other holder has approved it.
+// @Tags Instructions
+// @Accept json
+// @Produce json
+// @Param id path int true "Instruction ID"
+// @Param body body RespondInstructionRequest true "Approving holder"
+// @Success 200 {object} map[string]interface{} "Instruction approved"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/instruction/{id}/approve [post]
+func ApproveInstruction(context *gin.Context) {
+	var input RespondInstructionRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	instruction, err := models.ApproveInstruction(uint(ID), input.CustomerID)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Instruction": instruction})
+}
+
+// RejectInstruction rejects a pending instruction on behalf of a holder.
+// @Summary Reject an instruction
+// @Description Reject a pending debit instruction so that it is never executed
+// @Tags Instructions
+// @Accept json
+// @Produce json
+// @Param id path int true "Instruction ID"
+// @Param body body RespondInstructionRequest true "Rejecting holder"
+// @Success 200 {object} map[string]interface{} "Instruction rejected"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/instruction/{id}/reject [post]
+func RejectInstruction(context *gin.Context) {
+	var input RespondInstructionRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	instruction, err := models.RejectInstruction(uint(ID), input.CustomerID)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Instruction": instruction})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// kycMaxFileSize is the largest document scan accepted, in bytes.
+const kycMaxFileSize = 5 << 20
+
+// SubmitKYCRequest represents the form fields sent with a KYC document upload.
+type SubmitKYCRequest struct {
+	DocumentType   string `form:"document_type" binding:"required"`
+	DocumentNumber string `form:"document_number" binding:"required"`
+	Actor          string `form:"actor" binding:"required"`
+}
+
+// ReviewKYCRequest represents the request structure for reviewing a KYC document.
+type ReviewKYCRequest struct {
+	Status string `json:"status" binding:"required,oneof=verified rejected"`
+	Note   string `json:"note"`
+	Actor  string `json:"actor" binding:"required"`
+}
+
+// SubmitKYC uploads a KYC document of a customer for review.
+// @Summary Submit a KYC document
+// @Description Upload the scan of a customer's identity document. It is stored in the blob store and awaits review.
+// @Tags KYC
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param document_type formData string true "passport, aadhaar, voter_id, driving_licence or pan"
+// @Param document_number formData string true "Document number"
+// @Param actor formData string true "Actor"
+// @Param file formData file true "Document scan, at most 5 MB"
+// @Success 201 {object} map[string]interface{} "KYC document submitted successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/customer/{id}/kyc [post]
+func SubmitKYC(context *gin.Context) {
+	var input SubmitKYCRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	header, err := context.FormFile("file")
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "file is required"})
+		return
+	}
+	if header.Size > kycMaxFileSize {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "file must be at most 5 MB"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	record, err := models.SubmitKYC(uint(ID), input.DocumentType, input.DocumentNumber,
+		header.Filename, header.Header.Get("Content-Type"), file, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"KYCRecord": record})
+}
+
+// GetAllKYCRecordsByCustomerID retrieves the KYC documents of a customer.
+// @Summary Get KYC documents by customer ID
+// @Description Retrieve the KYC documents submitted for a customer, latest first, with their review status
+// @Tags KYC
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} map[string]interface{} "KYC documents retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/customer/{id}/kyc [get]
+func GetAllKYCRecordsByCustomerID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	records, err := models.FindAllKYCRecordsByCustomerID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"KYCRecord": records})
+}
+
+// GetKYCFile downloads the scan of a KYC document.
+// @Summary Download a KYC document
+// @Description Download the file uploaded with a KYC document
+// @Tags KYC
+// @Produce octet-stream
+// @Param id path int true "KYC record ID"
+// @Success 200 {file} file "Document scan"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/kyc/{id}/file [get]
+func GetKYCFile(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	record, file, err := models.OpenKYCFile(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	context.DataFromReader(http.StatusOK, -1, contentType, file, map[string]string{
+		"Content-Disposition": "attachment; filename=" + strconv.Quote(record.FileName),
+	})
+}
+
+// ReviewKYC verifies or rejects a KYC document.
+// @Summary Review a KYC document
+// @Description Verify or reject a pending KYC document. Verification sets the re-KYC due date and unfreezes accounts whose holders are all verified.
+// @Tags KYC
+// @Accept json
+// @Produce json
+// @Param id path int true "KYC record ID"
+// @Param body body ReviewKYCRequest true "Review"
+// @Success 200 {object} map[string]interface{} "KYC document reviewed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/kyc/{id}/review [post]
+func ReviewKYC(context *gin.Context) {
+	var input ReviewKYCRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	record, err := models.ReviewKYC(uint(ID), input.Status, input.Note, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"KYCRecord": record})
+}
+
+// GetAllReKYCDueByBranchID retrieves the customers of a branch due for re-KYC.
+// @Summary Get customers due for re-KYC
+// @Description Retrieve the customers of a branch whose KYC has expired or falls due within 30 days
+// @Tags KYC
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} map[string]interface{} "Customers due for re-KYC retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/branch/{id}/kyc/due [get]
+func GetAllReKYCDueByBranchID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	records, err := models.FindAllReKYCDueByBranchID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"KYCRecord": records})
+}
@@ -1,4 +1,3 @@
-
 package handlers
 
 import (
@@ -10,57 +9,68 @@ import (
 	"github.com/google/uuid"
 )
 
-// AddNomineeRequest represents the request structure for adding a nominee to an account.
-type AddNomineeRequest struct {
-	NomineeID     uint      `json:"nominee_id" binding:"required"`
-	AccountNumber uuid.UUID `json:"account_number" binding:"required"`
+// NomineeRequest represents one nominee of an account. A nominee need not be a customer;
+// when CustomerID is given the name and date of birth default to the customer's.
+type NomineeRequest struct {
+	CustomerID   uint    `json:"customer_id"`
+	Name         string  `json:"name"`
+	Relationship string  `json:"relationship" binding:"required"`
+	DateOfBirth  string  `json:"date_of_birth"`
+	Share        float64 `json:"share" binding:"required"`
+}
+
+// SetNomineesRequest represents the request structure for replacing the nominees of an account.
+type SetNomineesRequest struct {
+	AccountNumber uuid.UUID        `json:"account_number" binding:"required"`
+	Nominees      []NomineeRequest `json:"nominees" binding:"dive"`
 }
 
-// AddNominee adds a nominee to an account.
-// @Summary Add a nominee to an account
-// @Description Add a nominee to an account
+// SetNominees replaces the nominees of an account.
+// @Summary Set the nominees of an account
+// @Description Replace the nominees of an account. Shares must add up to 100 percent. Nominees have no transaction rights.
 // @Tags Nominees
 // @Accept json
 // @Produce json
-// @Param body body AddNomineeRequest true "Add nominee request"
-// @Success 200 {object} map[string]interface{} "Message: Nominee added"
+// @Param body body SetNomineesRequest true "Nominees of the account"
+// @Success 200 {object} map[string]interface{} "Nominees saved"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
-// @Router /customer/account/nominee [post]
-func AddNominee(context *gin.Context) {
-	var input AddNomineeRequest
+// @Router /customer/account/nominee [put]
+func SetNominees(context *gin.Context) {
+	var input SetNomineesRequest
 
 	if err := context.ShouldBind(&input); err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	account, err := models.FindAccountByAccountNumber(input.AccountNumber)
-	if err != nil {
-		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
-		return
-	}
-
-	mapping := models.CustomerToAccount{
-		CustomerID: input.NomineeID,
-		AccountID:  account.ID,
+	nominees := make([]models.CustomerToAccount, 0, len(input.Nominees))
+	for _, nominee := range input.Nominees {
+		nominees = append(nominees, models.CustomerToAccount{
+			NomineeCustomerID: nominee.CustomerID,
+			Name:              nominee.Name,
+			Relationship:      nominee.Relationship,
+			DateOfBirth:       nominee.DateOfBirth,
+			SharePercentage:   nominee.Share,
+		})
 	}
 
-	err = mapping.Save()
+	savedNominees, err := models.SetNominees(input.AccountNumber, nominees)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, map[string]interface{}{"Message": "Nominee added"})
+	context.JSON(http.StatusOK, map[string]interface{}{"Nominees": savedNominees})
 }
 
-// DeleteNomineeFromAccountByID deletes a nominee from an account by ID.
+// DeleteNomineeFromAccountByID deletes a nominee from an account by its
+// nominee ID, which is the ID of the nominee entry and not a customer ID.
 // @Summary Delete a nominee from an account by ID
-// @Description Delete a nominee from an account by ID
+// @Description Delete a nominee from an account. The ID is that of the nominee entry, as listed by GET /customer/account/{number}/nominee, not the customer ID of the nominee. The shares of the remaining nominees are scaled up to add up to 100 percent.
 // @Tags Nominees
 // @Produce json
 // @Param number path string true "Account number"
-// @Param id path int true "Nominee ID"
+// @Param id path int true "Nominee entry ID, not a customer ID"
 // @Success 200 {object} map[string]interface{} "Message: Nominee deleted"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
 // @Router /customer/account/{number}/nominee/{id} [delete]
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// misDashboard reads the period and top query parameters and responds with
+// the dashboard of scope.
+func misDashboard(context *gin.Context, scope models.MISScope) {
+	to := time.Now()
+	if value := context.Query("to"); value != "" {
+		parsed, err := time.Parse(statementDateLayout, value)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if value := context.Query("from"); value != "" {
+		parsed, err := time.Parse(statementDateLayout, value)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+		from = parsed
+	}
+
+	top, err := strconv.Atoi(context.DefaultQuery("top", "0"))
+	if err != nil || top < 0 {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "top must be a positive number"})
+		return
+	}
+
+	dashboard, err := models.FindMISDashboard(scope, from, to, top)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Dashboard": dashboard})
+}
+
+// GetBranchDashboard retrieves the MIS dashboard of a branch.
+// @Summary Get the dashboard of a branch
+// @Description Deposits by product, account openings and closures per day, transaction volumes by type and mode per day, and the accounts with the largest balances
+// @Tags Dashboards
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Param from query string false "First day (YYYY-MM-DD), defaults to 30 days before the last day"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Param top query int false "Number of accounts by balance, at most 100"
+// @Success 200 {object} map[string]interface{} "Dashboard retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/branch/{id}/dashboard [get]
+func GetBranchDashboard(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	misDashboard(context, models.MISScope{BranchID: uint(ID)})
+}
+
+// GetBankDashboard retrieves the MIS dashboard of a bank.
+// @Summary Get the dashboard of a bank
+// @Description Deposits by product, account openings and closures per day, transaction volumes by type and mode per day, and the accounts with the largest balances across all branches of a bank
+// @Tags Dashboards
+// @Produce json
+// @Param id path int true "Bank ID"
+// @Param from query string false "First day (YYYY-MM-DD), defaults to 30 days before the last day"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Param top query int false "Number of accounts by balance, at most 100"
+// @Success 200 {object} map[string]interface{} "Dashboard retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/bank/{id}/dashboard [get]
+func GetBankDashboard(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	misDashboard(context, models.MISScope{BankID: uint(ID)})
+}
+
+// GetSystemDashboard retrieves the MIS dashboard of every bank.
+// @Summary Get the system-wide dashboard
+// @Description Deposits by product, account openings and closures per day, transaction volumes by type and mode per day, and the accounts with the largest balances across all banks
+// @Tags Dashboards
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD), defaults to 30 days before the last day"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Param top query int false "Number of accounts by balance, at most 100"
+// @Success 200 {object} map[string]interface{} "Dashboard retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/dashboard [get]
+func GetSystemDashboard(context *gin.Context) {
+	misDashboard(context, models.MISScope{})
+}
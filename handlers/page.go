@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shouryagautam/bankdeploy/models"
+)
+
+// listOptions reads the limit, cursor, sort and order query parameters
+// shared by all list endpoints.
+func listOptions(context *gin.Context) (models.ListOptions, error) {
+	opts := models.ListOptions{
+		Cursor: context.Query("cursor"),
+		Sort:   context.Query("sort"),
+	}
+
+	if value := context.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return opts, errors.New("limit must be a positive number")
+		}
+		opts.Limit = limit
+	}
+
+	switch context.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		opts.Desc = true
+	default:
+		return opts, errors.New("order must be asc or desc")
+	}
+
+	return opts, nil
+}
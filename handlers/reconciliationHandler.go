@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReconciliationRequest represents the request structure for running a balance reconciliation.
+type ReconciliationRequest struct {
+	Actor string `json:"actor" binding:"required"`
+}
+
+// ApproveBalanceRepairRequest represents the request structure for approving a balance repair.
+type ApproveBalanceRepairRequest struct {
+	SupervisorID uint `json:"supervisor_id" binding:"required"`
+}
+
+// RequestBalanceRepairRequest represents the request structure for asking to repair a balance discrepancy.
+type RequestBalanceRepairRequest struct {
+	Note  string `json:"note" binding:"required"`
+	Actor string `json:"actor" binding:"required"`
+}
+
+// ReconcileBalances runs a balance reconciliation.
+// @Summary Reconcile balances
+// @Description Replay the transactions of every account from its last reconciled snapshot and report the accounts whose balance does not match
+// @Tags Reconciliation
+// @Accept json
+// @Produce json
+// @Param body body ReconciliationRequest true "Actor"
+// @Success 200 {object} map[string]interface{} "Reconciliation completed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/reconciliation [post]
+func ReconcileBalances(context *gin.Context) {
+	var input ReconciliationRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	run, err := models.ReconcileBalances(input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"ReconciliationRun": run})
+}
+
+// GetAllReconciliationRuns retrieves the balance reconciliation runs.
+// @Summary Get all reconciliation runs
+// @Description Retrieve the balance reconciliation runs, latest first, with their account and discrepancy counts
+// @Tags Reconciliation
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Reconciliation runs retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/reconciliation [get]
+func GetAllReconciliationRuns(context *gin.Context) {
+	runs, err := models.FindAllReconciliationRuns()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"ReconciliationRun": runs})
+}
+
+// GetReconciliationRunByID retrieves the discrepancy report of a reconciliation run.
+// @Summary Get a reconciliation run by ID
+// @Description Retrieve a reconciliation run with each discrepancy found: the account, the expected and actual balances and the first transaction that may have diverged
+// @Tags Reconciliation
+// @Produce json
+// @Param id path int true "Reconciliation run ID"
+// @Success 200 {object} map[string]interface{} "Reconciliation run retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/reconciliation/{id} [get]
+func GetReconciliationRunByID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	run, err := models.FindReconciliationRunByID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"ReconciliationRun": run})
+}
+
+// RequestBalanceRepair asks for a balance discrepancy to be repaired.
+// @Summary Request a balance repair
+// @Description Ask for an adjustment entry that sets the balance of the account to the amount its transactions add up to. A supervisor other than the requester must approve it.
+// @Tags Reconciliation
+// @Accept json
+// @Produce json
+// @Param id path int true "Discrepancy ID"
+// @Param body body RequestBalanceRepairRequest true "Repair request"
+// @Success 200 {object} map[string]interface{} "Repair requested successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/discrepancy/{id}/repair [post]
+func RequestBalanceRepair(context *gin.Context) {
+	var input RequestBalanceRepairRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	discrepancy, err := models.RequestBalanceRepair(uint(ID), input.Note, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"BalanceDiscrepancy": discrepancy})
+}
+
+// ApproveBalanceRepair approves a requested balance repair and posts its adjustment entry.
+// @Summary Approve a balance repair
+// @Description As a supervisor, approve a requested repair. The supervisor must be an active supervisor of the account's bank other than the requester. The account is checked again and the adjustment entry posted only if the discrepancy is unchanged.
+// @Tags Reconciliation
+// @Accept json
+// @Produce json
+// @Param id path int true "Discrepancy ID"
+// @Param body body ApproveBalanceRepairRequest true "Supervisor"
+// @Success 200 {object} map[string]interface{} "Repair approved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /super/discrepancy/{id}/approve [post]
+func ApproveBalanceRepair(context *gin.Context) {
+	var input ApproveBalanceRepairRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	discrepancy, err := models.ApproveBalanceRepair(uint(ID), input.SupervisorID)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"BalanceDiscrepancy": discrepancy})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateStaffRequest represents the request structure for adding a staff member.
+type CreateStaffRequest struct {
+	BranchID uint   `json:"branch_id" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=officer supervisor"`
+}
+
+// CreateStaff adds a staff member to a branch.
+// @Summary Add a staff member
+// @Description Add an officer or supervisor to a branch. Supervisors approve postings that need an override.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param body body CreateStaffRequest true "Staff member to be added"
+// @Success 201 {object} map[string]interface{} "Staff member added successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /admin/staff [post]
+func CreateStaff(context *gin.Context) {
+	var input CreateStaffRequest
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	staff := models.Staff{
+		BranchID: input.BranchID,
+		Name:     input.Name,
+		Role:     input.Role,
+	}
+
+	savedStaff, err := staff.Save()
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"Staff": savedStaff})
+}
+
+// GetStaffByID retrieves a staff member by ID.
+// @Summary Get a staff member by ID
+// @Description Retrieve a staff member by ID
+// @Tags Staff
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Success 200 {object} map[string]interface{} "Staff member retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /admin/staff/{id} [get]
+func GetStaffByID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	staff, err := models.FindStaffByID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Staff": staff})
+}
+
+// DeactivateStaff stops a staff member from approving anything further.
+// @Summary Deactivate a staff member
+// @Description Stop a staff member from approving anything further
+// @Tags Staff
+// @Produce json
+// @Param id path int true "Staff ID"
+// @Success 200 {object} map[string]interface{} "Staff member deactivated successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /admin/staff/{id}/deactivate [post]
+func DeactivateStaff(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	staff, err := models.DeactivateStaff(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Staff": staff})
+}
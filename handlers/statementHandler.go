@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/shouryagautam/bankdeploy/models"
+	"github.com/shouryagautam/bankdeploy/pdf"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const statementDateLayout = "2006-01-02"
+
+// GetStatementByAccountNumber generates the statement of an account.
+// @Summary Get an account statement
+// @Description Generate the statement of an account for a date range with opening, running and closing balances, as JSON, CSV or PDF
+// @Tags Transactions
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param number path string true "Account number"
+// @Param from query string false "First day of the statement (YYYY-MM-DD), defaults to 30 days before the last day"
+// @Param to query string false "Last day of the statement (YYYY-MM-DD), defaults to today"
+// @Param format query string false "json, csv or pdf"
+// @Success 200 {object} map[string]interface{} "Statement generated successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/account/{number}/statement [get]
+func GetStatementByAccountNumber(context *gin.Context) {
+	number, err := uuid.Parse(context.Param("number"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	to := time.Now().Truncate(24 * time.Hour)
+	if value := context.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(statementDateLayout, value, time.Local)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if value := context.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(statementDateLayout, value, time.Local)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+		from = parsed
+	}
+
+	statement, err := models.GenerateStatement(number, from, to)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s", from.Format(statementDateLayout), to.Format(statementDateLayout))
+
+	switch context.DefaultQuery("format", "json") {
+	case "json":
+		context.JSON(http.StatusOK, map[string]interface{}{"Statement": statement})
+	case "csv":
+		context.Header("Content-Disposition", "attachment; filename="+filename+".csv")
+		context.Data(http.StatusOK, "text/csv", statementCSV(statement))
+	case "pdf":
+		context.Header("Content-Disposition", "attachment; filename="+filename+".pdf")
+		context.Data(http.StatusOK, "application/pdf", statementPDF(statement))
+	default:
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "format must be json, csv or pdf"})
+	}
+}
+
+func formatAmount(amount float64) string {
+	if amount == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func statementCSV(statement *models.Statement) []byte {
+	var out bytes.Buffer
+	writer := csv.NewWriter(&out)
+
+	writer.Write([]string{"Date", "Description", "Reference", "Debit", "Credit", "Balance"})
+	writer.Write([]string{statement.From.Format(statementDateLayout), "Opening balance", "", "", "", fmt.Sprintf("%.2f", statement.OpeningBalance)})
+	for _, line := range statement.Lines {
+		writer.Write([]string{
+			line.Time.Format(statementDateLayout),
+			line.Description,
+			line.Reference,
+			formatAmount(line.Debit),
+			formatAmount(line.Credit),
+			fmt.Sprintf("%.2f", line.Balance),
+		})
+	}
+	writer.Write([]string{statement.To.Format(statementDateLayout), "Closing balance", "", fmt.Sprintf("%.2f", statement.TotalDebits), fmt.Sprintf("%.2f", statement.TotalCredits), fmt.Sprintf("%.2f", statement.ClosingBalance)})
+
+	writer.Flush()
+	return out.Bytes()
+}
+
+func statementPDF(statement *models.Statement) []byte {
+	doc := pdf.New()
+	row := func(date string, description string, reference string, debit string, credit string, balance string) string {
+		return fmt.Sprintf("%-10s %-30.30s %-14.14s %11s %11s %13s", date, description, reference, debit, credit, balance)
+	}
+	rule := strings.Repeat("-", pdf.LineWidth)
+
+	doc.Heading(statement.BankName)
+	doc.Line(statement.BranchAddress)
+	doc.Line("IFSC: " + statement.IFSC)
+	doc.Line("")
+	doc.Heading("ACCOUNT STATEMENT")
+	doc.Line("Account number: " + statement.AccountNumber)
+	doc.Line("Account type:   " + statement.AccountType)
+	doc.Line("Holders:        " + strings.Join(statement.Holders, ", "))
+	doc.Line("Period:         " + statement.From.Format(statementDateLayout) + " to " + statement.To.Format(statementDateLayout))
+	doc.Line("")
+	doc.Heading(row("Date", "Description", "Reference", "Debit", "Credit", "Balance"))
+	doc.Line(rule)
+	doc.Line(row(statement.From.Format(statementDateLayout), "Opening balance", "", "", "", fmt.Sprintf("%.2f", statement.OpeningBalance)))
+	for _, line := range statement.Lines {
+		doc.Line(row(line.Time.Format(statementDateLayout), line.Description, line.Reference,
+			formatAmount(line.Debit), formatAmount(line.Credit), fmt.Sprintf("%.2f", line.Balance)))
+	}
+	doc.Line(rule)
+	doc.Heading(row(statement.To.Format(statementDateLayout), "Closing balance", "",
+		fmt.Sprintf("%.2f", statement.TotalDebits), fmt.Sprintf("%.2f", statement.TotalCredits), fmt.Sprintf("%.2f", statement.ClosingBalance)))
+
+	return doc.Bytes()
+}
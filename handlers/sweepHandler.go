@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SaveSweepRuleRequest represents the request structure for configuring an account's sweep rule.
+type SaveSweepRuleRequest struct {
+	Threshold float64 `json:"threshold" binding:"required"`
+	Unit      float64 `json:"unit" binding:"required"`
+	Actor     string  `json:"actor" binding:"required"`
+}
+
+// DisableSweepRuleRequest represents the request structure for disabling an account's sweep rule.
+type DisableSweepRuleRequest struct {
+	Actor string `json:"actor" binding:"required"`
+}
+
+// SaveSweepRule configures automatic sweeps for an account.
+// @Summary Configure a sweep rule
+// @Description Sweep the balance above a threshold into a linked deposit in units, and break units back to cover debits
+// @Tags Sweeps
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body SaveSweepRuleRequest true "Sweep rule"
+// @Success 200 {object} map[string]interface{} "Sweep rule saved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/sweep [put]
+func SaveSweepRule(context *gin.Context) {
+	var input SaveSweepRuleRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	rule, err := models.SaveSweepRule(uint(ID), input.Threshold, input.Unit, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"SweepRule": rule})
+}
+
+// GetSweepRuleByAccountID retrieves the sweep rule of an account.
+// @Summary Get a sweep rule by account ID
+// @Description Retrieve the sweep rule of an account along with its linked deposit
+// @Tags Sweeps
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} map[string]interface{} "Sweep rule retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/sweep [get]
+func GetSweepRuleByAccountID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	rule, err := models.FindSweepRuleByAccountID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"SweepRule": rule})
+}
+
+// RunSweep sweeps an account's surplus into its linked deposit now.
+// @Summary Run a sweep
+// @Description Sweep the surplus above the threshold into the linked deposit without waiting for end of day
+// @Tags Sweeps
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} map[string]interface{} "Amount swept"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/sweep/run [post]
+func RunSweep(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	amount, err := models.RunSweep(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Swept": amount})
+}
+
+// DisableSweepRule stops sweeping for an account.
+// @Summary Disable a sweep rule
+// @Description Stop sweeping and break the whole linked deposit back into the account
+// @Tags Sweeps
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param body body DisableSweepRuleRequest true "Actor"
+// @Success 200 {object} map[string]interface{} "Sweep rule disabled"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/{id}/sweep/disable [post]
+func DisableSweepRule(context *gin.Context) {
+	var input DisableSweepRuleRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	rule, err := models.DisableSweepRule(uint(ID), input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"SweepRule": rule})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/shouryagautam/bankdeploy/models"
+	"github.com/shouryagautam/bankdeploy/pdf"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SaveTDSExemptionRequest represents the request structure for recording a customer's tax exemption declaration.
+type SaveTDSExemptionRequest struct {
+	FinancialYear string `json:"financialYear" binding:"required"`
+	Form          string `json:"form" binding:"required,oneof=15G 15H"`
+	Actor         string `json:"actor" binding:"required"`
+}
+
+// SaveTDSExemption records a customer's declaration that no tax is to be withheld.
+// @Summary Record a tax exemption declaration
+// @Description Record a form 15G or 15H declaration of a customer for a financial year, such as 2026-27. No tax is withheld from interest credited afterwards in that year.
+// @Tags TDS
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param body body SaveTDSExemptionRequest true "Declaration"
+// @Success 200 {object} map[string]interface{} "Declaration recorded successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/customer/{id}/tds/exemption [put]
+func SaveTDSExemption(context *gin.Context) {
+	var input SaveTDSExemptionRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	exemption, err := models.SaveTDSExemption(uint(ID), input.FinancialYear, input.Form, input.Actor)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"TDSExemption": exemption})
+}
+
+// GetAllTDSLedgerEntries retrieves the tax ledger of a customer.
+// @Summary Get the tax ledger of a customer
+// @Description Retrieve every interest credit of a customer in a financial year with the rate applied and the tax withheld
+// @Tags TDS
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Param year query string false "Financial year such as 2026-27, defaults to the current one"
+// @Success 200 {object} map[string]interface{} "Ledger retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/customer/{id}/tds [get]
+func GetAllTDSLedgerEntries(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	entries, err := models.FindAllTDSLedgerEntries(uint(ID), context.DefaultQuery("year", models.CurrentFinancialYear()))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"TDSLedgerEntry": entries})
+}
+
+// GetTDSCertificate generates the tax withholding certificate of a customer.
+// @Summary Get a TDS certificate
+// @Description Summarise the interest paid to a customer in a financial year and the tax withheld from it per quarter, as JSON or PDF
+// @Tags TDS
+// @Produce json
+// @Produce application/pdf
+// @Param id path int true "Customer ID"
+// @Param year query string false "Financial year such as 2026-27, defaults to the current one"
+// @Param format query string false "json or pdf"
+// @Success 200 {object} map[string]interface{} "Certificate generated successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/{id}/tds/certificate [get]
+func GetTDSCertificate(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	certificate, err := models.GenerateTDSCertificate(uint(ID), context.DefaultQuery("year", models.CurrentFinancialYear()))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	switch context.DefaultQuery("format", "json") {
+	case "json":
+		context.JSON(http.StatusOK, map[string]interface{}{"TDSCertificate": certificate})
+	case "pdf":
+		context.Header("Content-Disposition", "attachment; filename=tds-certificate-"+certificate.FinancialYear+".pdf")
+		context.Data(http.StatusOK, "application/pdf", tdsCertificatePDF(certificate))
+	default:
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "format must be json or pdf"})
+	}
+}
+
+func tdsCertificatePDF(certificate *models.TDSCertificate) []byte {
+	doc := pdf.New()
+	row := func(quarter string, period string, credits string, interest string, tax string) string {
+		return fmt.Sprintf("%-8s %-24s %8s %16s %16s", quarter, period, credits, interest, tax)
+	}
+	rule := strings.Repeat("-", pdf.LineWidth)
+
+	pan := certificate.PAN
+	if pan == "" {
+		pan = "Not provided"
+	}
+
+	doc.Heading(certificate.BankName)
+	doc.Line(certificate.BranchAddress)
+	doc.Line("IFSC: " + certificate.IFSC)
+	doc.Line("")
+	doc.Heading("CERTIFICATE OF TAX DEDUCTED AT SOURCE ON INTEREST")
+	doc.Line("Customer:       " + certificate.CustomerName)
+	doc.Line("Customer ID:    " + strconv.FormatUint(uint64(certificate.CustomerID), 10))
+	doc.Line("PAN:            " + pan)
+	doc.Line("Financial year: " + certificate.FinancialYear)
+	if certificate.Exempt {
+		doc.Line("A declaration for exemption from withholding is on record for this year.")
+	}
+	doc.Line("")
+	doc.Heading(row("Quarter", "Period", "Credits", "Interest paid", "Tax withheld"))
+	doc.Line(rule)
+	for _, quarter := range certificate.Quarters {
+		doc.Line(row(
+			fmt.Sprintf("Q%d", quarter.Quarter),
+			quarter.From.Format(statementDateLayout)+" to "+quarter.To.Format(statementDateLayout),
+			strconv.Itoa(quarter.Credits),
+			fmt.Sprintf("%.2f", quarter.InterestPaid),
+			fmt.Sprintf("%.2f", quarter.TaxWithheld),
+		))
+	}
+	doc.Line(rule)
+	doc.Heading(row("Total", "", "", fmt.Sprintf("%.2f", certificate.InterestPaid), fmt.Sprintf("%.2f", certificate.TaxWithheld)))
+
+	return doc.Bytes()
+}
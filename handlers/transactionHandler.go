@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/shouryagautam/bankdeploy/models"
 	"net/http"
 	"strconv"
@@ -32,10 +33,15 @@ func Deposit(context *gin.Context) {
 		AccountID:          input.AccountID,
 		Amount:             input.Amount,
 		ModeOfPayment:      input.ModeOfPayment,
-		TypeOfTransaction: "Deposit",
+		TypeOfTransaction: models.TRANSACTION_DEPOSIT,
 		Time:               time.Now(),
 	}
 
+	if err := models.StampPostingDates(&transaction); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
 	err := models.AccountDeposit(transaction.AccountID, transaction.Amount)
 	if err != nil {
 		context.JSON(http.StatusNotModified, map[string]interface{}{"error": err.Error()})
@@ -72,11 +78,27 @@ func Withdraw(context *gin.Context) {
 		AccountID:          input.AccountID,
 		Amount:             input.Amount,
 		ModeOfPayment:      input.ModeOfPayment,
-		TypeOfTransaction: "Withdraw",
+		TypeOfTransaction: models.TRANSACTION_WITHDRAW,
+		InitiatedBy:        input.InitiatedBy,
 		Time:               time.Now(),
 	}
 
-	err := models.AccountWithdrawal(transaction.AccountID, transaction.Amount)
+	if err := models.StampPostingDates(&transaction); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	instruction, err := models.ApplyOperatingMode(&transaction)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if instruction != nil {
+		context.JSON(http.StatusAccepted, map[string]interface{}{"message": "Your Transaction is awaiting approval from the other holders", "data": instruction})
+		return
+	}
+
+	err = models.AccountWithdrawal(transaction.AccountID, transaction.Amount, transaction.InitiatedBy)
 	if err != nil {
 		context.JSON(http.StatusBadGateway, map[string]interface{}{"error": err.Error()})
 		return
@@ -93,7 +115,7 @@ func Withdraw(context *gin.Context) {
 
 // Transfer handles transferring money between accounts.
 // @Summary Transfer money between accounts
-// @Description Transfer money between accounts
+// @Description Transfer money between accounts. The receiver is given either as an account number or as a VPA.
 // @Tags Transactions
 // @Accept json
 // @Produce json
@@ -112,12 +134,38 @@ func Transfer(context *gin.Context) {
 		AccountID:             input.AccountID,
 		Amount:                input.Amount,
 		ModeOfPayment:         input.ModeOfPayment,
-		TypeOfTransaction:     "Transfer",
+		TypeOfTransaction:     models.TRANSACTION_TRANSFER,
 		ReceiverAccountNumber: input.ReceiverAccountNumber,
+		ReceiverVPA:           input.ReceiverVPA,
+		InitiatedBy:           input.InitiatedBy,
 		Time:                  time.Now(),
 	}
 
-	err := models.AccountTransfer(transaction.AccountID, transaction.ReceiverAccountNumber, transaction.Amount)
+	if transaction.ReceiverVPA != "" {
+		receiver, err := models.FindAccountByVPA(transaction.ReceiverVPA)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		transaction.ReceiverAccountNumber = receiver.AccountNumber
+	}
+
+	if err := models.StampPostingDates(&transaction); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	instruction, err := models.ApplyOperatingMode(&transaction)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if instruction != nil {
+		context.JSON(http.StatusAccepted, map[string]interface{}{"message": "Your Transaction is awaiting approval from the other holders", "data": instruction})
+		return
+	}
+
+	err = models.AccountTransfer(transaction.AccountID, transaction.ReceiverAccountNumber, transaction.Amount, transaction.InitiatedBy)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
@@ -132,25 +180,163 @@ func Transfer(context *gin.Context) {
 	context.JSON(http.StatusAccepted, map[string]interface{}{"message": "Your Transaction has been completed successfully", "data": savedTransaction})
 }
 
+// BackDatedPostingRequest represents the request structure for a back-dated posting.
+type BackDatedPostingRequest struct {
+	AccountID             uint      `json:"account_id" binding:"required"`
+	TypeOfTransaction     string    `json:"type_of_transaction" binding:"required,oneof=Deposit Withdraw Transfer"`
+	Amount                float64   `json:"amount" binding:"required,gt=0"`
+	ModeOfPayment         string    `json:"mode_of_payment" binding:"required"`
+	ReceiverAccountNumber uuid.UUID `json:"receiver_account_number"`
+	InitiatedBy           uint      `json:"initiated_by"`
+	ValueDate             time.Time `json:"value_date" binding:"required"`
+	SupervisorID          uint      `json:"supervisor_id" binding:"required"`
+}
+
+// PostBackDated books a posting whose value date falls on a closed business day.
+// @Summary Post a back-dated transaction
+// @Description Book a deposit, withdrawal or transfer with a value date on a business day that has already been closed. The supervisor must be an active supervisor of the account's bank and is recorded as the override.
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param body body BackDatedPostingRequest true "Back-dated posting"
+// @Success 202 {object} map[string]interface{} "message: Your Transaction has been completed successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /manager/account/backdated [post]
+func PostBackDated(context *gin.Context) {
+	var input BackDatedPostingRequest
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	if input.TypeOfTransaction == models.TRANSACTION_TRANSFER && input.ReceiverAccountNumber == uuid.Nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "a transfer needs a receiver account number"})
+		return
+	}
+
+	transaction := models.Transaction{
+		AccountID:             input.AccountID,
+		Amount:                input.Amount,
+		ModeOfPayment:         input.ModeOfPayment,
+		TypeOfTransaction:     input.TypeOfTransaction,
+		ReceiverAccountNumber: input.ReceiverAccountNumber,
+		InitiatedBy:           input.InitiatedBy,
+		Time:                  time.Now(),
+		ValueDate:             input.ValueDate,
+	}
+
+	savedTransaction, err := models.PostBackDated(&transaction, input.SupervisorID)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusAccepted, map[string]interface{}{"message": "Your Transaction has been completed successfully", "data": savedTransaction})
+}
+
 // GetAllTransactionsByAccountNumber retrieves all transactions by account number.
 // @Summary Get all transactions by account number
 // @Description Retrieve all transactions by account number
 // @Tags Transactions
 // @Produce json
 // @Param number path string true "Account number"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param min_amount query number false "Smallest amount"
+// @Param max_amount query number false "Largest amount"
+// @Param type query string false "Type of transaction"
+// @Param mode query string false "Mode of payment"
+// @Param limit query int false "Page size, at most 500"
+// @Param cursor query string false "Cursor returned with the previous page"
+// @Param sort query string false "id, time or amount"
+// @Param order query string false "asc or desc"
 // @Success 200 {object} map[string]interface{} "Transactions retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "error: Bad request"
 // @Router /customer/account/{number}/transactions [get]
 func GetAllTransactionsByAccountNumber(context *gin.Context) {
 	number := uuid.MustParse(context.Param("number"))
 
-	transactions, err := models.FindAllTransactionsByAccountNumber(number)
+	opts, err := listOptions(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	filter, err := transactionFilter(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	transactions, page, err := models.FindAllTransactionsByAccountNumber(number, filter, opts)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Transactions": transactions, "Page": page})
+}
+
+// SearchTransactionsByAccountNumber searches the debits and credits of an account.
+// @Summary Search the transactions of an account
+// @Description Search the debits and credits of an account, each marked with its direction and counterparty
+// @Tags Transactions
+// @Produce json
+// @Param number path string true "Account number"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param amount query number false "Exact amount"
+// @Param min_amount query number false "Smallest amount"
+// @Param max_amount query number false "Largest amount"
+// @Param type query string false "Type of transaction"
+// @Param mode query string false "Mode of payment"
+// @Param reference query string false "Reference number"
+// @Param q query string false "Narration text"
+// @Param limit query int false "Page size, at most 500"
+// @Param cursor query string false "Cursor returned with the previous page"
+// @Param sort query string false "id, time or amount"
+// @Param order query string false "asc or desc"
+// @Success 200 {object} map[string]interface{} "Transactions retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/account/{number}/transactions/search [get]
+func SearchTransactionsByAccountNumber(context *gin.Context) {
+	number, err := uuid.Parse(context.Param("number"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	opts, err := listOptions(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	filter, err := transactionFilter(context)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, map[string]interface{}{"Transactions": transactions})
+	if value := context.Query("amount"); value != "" {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount <= 0 {
+			context.JSON(http.StatusBadRequest, map[string]interface{}{"error": "amount must be a positive number"})
+			return
+		}
+		filter.MinAmount = amount
+		filter.MaxAmount = amount
+	}
+	filter.Reference = context.Query("reference")
+	filter.Text = context.Query("q")
+
+	results, page, err := models.SearchTransactionsByAccountNumber(number, filter, opts)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"Transactions": results, "Page": page})
 }
 
 // GetTransactionByID retrieves a transaction by its ID.
@@ -174,3 +360,44 @@ func GetTransactionByID(context *gin.Context) {
 
 	context.JSON(http.StatusOK, map[string]interface{}{"Transaction": transaction})
 }
+
+// transactionFilter reads the filters of a transaction list. The to date
+// is inclusive.
+func transactionFilter(context *gin.Context) (models.TransactionFilter, error) {
+	filter := models.TransactionFilter{
+		Type: context.Query("type"),
+		Mode: context.Query("mode"),
+	}
+
+	if value := context.Query("from"); value != "" {
+		from, err := time.ParseInLocation(statementDateLayout, value, time.Local)
+		if err != nil {
+			return filter, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		filter.From = from
+	}
+	if value := context.Query("to"); value != "" {
+		to, err := time.ParseInLocation(statementDateLayout, value, time.Local)
+		if err != nil {
+			return filter, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	if value := context.Query("min_amount"); value != "" {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount < 0 {
+			return filter, errors.New("min_amount must be a positive number")
+		}
+		filter.MinAmount = amount
+	}
+	if value := context.Query("max_amount"); value != "" {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount < 0 {
+			return filter, errors.New("max_amount must be a positive number")
+		}
+		filter.MaxAmount = amount
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"github.com/shouryagautam/bankdeploy/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RegisterVPARequest represents the request structure for registering a virtual payment address.
+type RegisterVPARequest struct {
+	CustomerID    uint      `json:"customer_id" binding:"required"`
+	Name          string    `json:"name" binding:"required"`
+	AccountNumber uuid.UUID `json:"account_number" binding:"required"`
+}
+
+// ChangeVPAAccountRequest represents the request structure for re-pointing a virtual payment address.
+type ChangeVPAAccountRequest struct {
+	Address       string    `json:"address" binding:"required"`
+	CustomerID    uint      `json:"customer_id" binding:"required"`
+	AccountNumber uuid.UUID `json:"account_number" binding:"required"`
+}
+
+// RegisterVPA registers a virtual payment address for a customer.
+// @Summary Register a VPA
+// @Description Register a virtual payment address mapped to one of the customer's accounts
+// @Tags VPAs
+// @Accept json
+// @Produce json
+// @Param body body RegisterVPARequest true "VPA to be registered"
+// @Success 201 {object} map[string]interface{} "VPA registered successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/vpa [post]
+func RegisterVPA(context *gin.Context) {
+	var input RegisterVPARequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	vpa, err := models.RegisterVPA(input.CustomerID, input.Name, input.AccountNumber)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusCreated, map[string]interface{}{"VPA": vpa})
+}
+
+// ResolveVPA resolves a virtual payment address to its masked holder name.
+// @Summary Resolve a VPA
+// @Description Resolve a virtual payment address to the masked name of its holder
+// @Tags VPAs
+// @Produce json
+// @Param address path string true "Virtual payment address"
+// @Success 200 {object} map[string]interface{} "VPA resolved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/vpa/{address} [get]
+func ResolveVPA(context *gin.Context) {
+	resolution, err := models.ResolveVPA(context.Param("address"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"VPA": resolution})
+}
+
+// GetAllVPAsByCustomerID retrieves all virtual payment addresses of a customer.
+// @Summary Get all VPAs by customer ID
+// @Description Retrieve all virtual payment addresses of a customer with the accounts they point to
+// @Tags VPAs
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} map[string]interface{} "VPAs retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/{id}/vpa [get]
+func GetAllVPAsByCustomerID(context *gin.Context) {
+	id := context.Param("id")
+	ID, _ := strconv.ParseUint(id, 10, 0)
+
+	vpas, err := models.FindAllVPAsByCustomerID(uint(ID))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"VPAs": vpas})
+}
+
+// ChangeVPAAccount points a virtual payment address at another account.
+// @Summary Change the account of a VPA
+// @Description Point a virtual payment address at another account held by the same customer
+// @Tags VPAs
+// @Accept json
+// @Produce json
+// @Param body body ChangeVPAAccountRequest true "VPA and new account"
+// @Success 200 {object} map[string]interface{} "VPA updated successfully"
+// @Failure 400 {object} map[string]interface{} "error: Bad request"
+// @Router /customer/vpa [put]
+func ChangeVPAAccount(context *gin.Context) {
+	var input ChangeVPAAccountRequest
+
+	if err := context.ShouldBind(&input); err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	vpa, err := models.ChangeVPAAccount(input.Address, input.CustomerID, input.AccountNumber)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	context.JSON(http.StatusOK, map[string]interface{}{"VPA": vpa})
+}
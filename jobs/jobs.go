@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/shouryagautam/bankdeploy/models"
+)
+
+// Start launches the periodic background jobs. Each job runs once
+// immediately and then on its own interval.
+func Start() {
+	go schedule("release expired holds", time.Hour, models.ReleaseExpiredHolds)
+	go schedule("mark dormant accounts", 24*time.Hour, models.MarkDormantAccounts)
+	go schedule("flag unclaimed deposits", 24*time.Hour, models.FlagUnclaimedDeposits)
+	go schedule("expire collect requests", time.Hour, models.ExpireCollectRequests)
+	go schedule("sweep surplus balances", 24*time.Hour, models.RunAllSweeps)
+	go schedule("expire pending instructions", time.Hour, models.ExpirePendingInstructions)
+	go schedule("convert accounts of minors who came of age", 24*time.Hour, models.ConvertMajorAccounts)
+	go schedule("refresh dashboards", time.Hour, models.RefreshMISViews)
+	go schedule("flag large cash transactions", time.Hour, models.FlagRecentCashTransactions)
+	go schedule("reconcile balances", 24*time.Hour, models.ReconcileAllBalances)
+	go schedule("track re-KYC due dates", 24*time.Hour, models.TrackReKYC)
+}
+
+func schedule(name string, interval time.Duration, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(); err != nil {
+			log.Printf("job %q failed: %s\n", name, err.Error())
+		}
+		<-ticker.C
+	}
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 
 	"github.com/joho/godotenv"
+	"github.com/shouryagautam/bankdeploy/blob"
 	"github.com/shouryagautam/bankdeploy/database"
 	_ "github.com/shouryagautam/bankdeploy/docs"
+	"github.com/shouryagautam/bankdeploy/jobs"
 	"github.com/shouryagautam/bankdeploy/models"
 	"github.com/shouryagautam/bankdeploy/routes"
 
@@ -17,8 +21,19 @@ func init() {
 }
 
 func main() {
+    reconcile := flag.Bool("reconcile", false, "reconcile account balances against their transactions and exit")
+    flag.Parse()
+
     LoadEnv()
+    blob.Configure()
     LoadDatabase()
+    Migrate()
+    if *reconcile {
+        Reconcile()
+        return
+    }
+    LoadViews()
+    jobs.Start()
     routes.Router()
     //DeleteDatabase()
 }
@@ -38,10 +53,43 @@ func LoadDatabase() error {
 	models := []interface{}{
         (*models.Bank)(nil),
         (*models.Branch)(nil),
+        (*models.Staff)(nil),
         (*models.Customer)(nil),
         (*models.Account)(nil),
         (*models.CustomerToAccount)(nil),
 		(*models.Transaction)(nil),
+		(*models.Hold)(nil),
+		(*models.AuditLog)(nil),
+		(*models.UnclaimedDeposit)(nil),
+		(*models.ChequeBook)(nil),
+		(*models.Cheque)(nil),
+		(*models.Card)(nil),
+		(*models.ATM)(nil),
+		(*models.Cassette)(nil),
+		(*models.ATMReplenishment)(nil),
+		(*models.ATMDispense)(nil),
+		(*models.VPA)(nil),
+		(*models.CollectRequest)(nil),
+		(*models.SweepRule)(nil),
+		(*models.PendingInstruction)(nil),
+		(*models.InstructionApproval)(nil),
+		(*models.DeceasedClaim)(nil),
+		(*models.ClaimDocument)(nil),
+		(*models.ClaimPayout)(nil),
+		(*models.Notification)(nil),
+		(*models.BusinessDay)(nil),
+		(*models.EODRun)(nil),
+		(*models.EODStep)(nil),
+		(*models.InterestAccrual)(nil),
+		(*models.DayEndReport)(nil),
+		(*models.CTRRule)(nil),
+		(*models.CTRFlag)(nil),
+		(*models.TDSExemption)(nil),
+		(*models.TDSLedgerEntry)(nil),
+		(*models.BalanceSnapshot)(nil),
+		(*models.ReconciliationRun)(nil),
+		(*models.BalanceDiscrepancy)(nil),
+		(*models.KYCRecord)(nil),
     }
 
 	opts := &orm.CreateTableOptions{
@@ -60,14 +108,82 @@ func LoadDatabase() error {
 }
 
 
+// Migrate adds the columns that LoadDatabase does not add to tables made
+// by an earlier version. The models rely on them, so the app does not
+// start if it fails.
+func Migrate() error {
+    err := models.MigrateColumns()
+    if err != nil {
+        log.Fatal(err.Error())
+    }
+    return nil
+}
+
+// LoadViews creates the views over the tables made by LoadDatabase.
+func LoadViews() error {
+    err := models.CreateMISViews()
+    if err != nil {
+        println(err.Error())
+    }
+    return nil
+}
+
+// Reconcile runs a balance reconciliation and prints its discrepancy report.
+func Reconcile() {
+    run, err := models.ReconcileBalances("cli")
+    if err != nil {
+        log.Fatal(err.Error())
+    }
+
+    fmt.Printf("reconciliation run %d: %d accounts, %d discrepancies\n", run.ID, run.Accounts, run.DiscrepancyCount)
+    for _, discrepancy := range run.Discrepancies {
+        fmt.Printf("account %d (%s): expected %.2f, actual %.2f, difference %.2f, first transaction %d\n",
+            discrepancy.AccountID, discrepancy.AccountNumber, discrepancy.Expected, discrepancy.Actual,
+            discrepancy.Difference, discrepancy.FirstTransactionID)
+    }
+}
+
 func DeleteDatabase() error {
     database.Connect()
 
     models := []interface{}{
+        (*models.KYCRecord)(nil),
+        (*models.BalanceDiscrepancy)(nil),
+        (*models.ReconciliationRun)(nil),
+        (*models.BalanceSnapshot)(nil),
+        (*models.TDSLedgerEntry)(nil),
+        (*models.TDSExemption)(nil),
+        (*models.CTRFlag)(nil),
+        (*models.CTRRule)(nil),
+        (*models.DayEndReport)(nil),
+        (*models.InterestAccrual)(nil),
+        (*models.EODStep)(nil),
+        (*models.EODRun)(nil),
+        (*models.BusinessDay)(nil),
+        (*models.Notification)(nil),
+        (*models.ClaimPayout)(nil),
+        (*models.ClaimDocument)(nil),
+        (*models.DeceasedClaim)(nil),
+        (*models.InstructionApproval)(nil),
+        (*models.PendingInstruction)(nil),
+        (*models.SweepRule)(nil),
+        (*models.CollectRequest)(nil),
+        (*models.VPA)(nil),
+        (*models.ATMDispense)(nil),
+        (*models.ATMReplenishment)(nil),
+        (*models.Cassette)(nil),
+        (*models.ATM)(nil),
+        (*models.Card)(nil),
+        (*models.Cheque)(nil),
+        (*models.ChequeBook)(nil),
+        (*models.UnclaimedDeposit)(nil),
+        (*models.AuditLog)(nil),
+        (*models.Hold)(nil),
         (*models.Transaction)(nil),
         (*models.CustomerToAccount)(nil),
         (*models.Account)(nil),
         (*models.Customer)(nil),
+        (*models.Staff)(nil),
         (*models.Branch)(nil),
         (*models.Bank)(nil),
     }
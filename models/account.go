@@ -4,6 +4,7 @@ import (
 	"github.com/shouryagautam/bankdeploy/database"
 	"context"
 	"errors"
+	"time"
 
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/google/uuid"
@@ -15,7 +16,20 @@ type Account struct{
 	Branch *Branch `pg:"rel:has-one"`
 	AccountNumber uuid.UUID `pg:"type:uuid"`
 	Balance float64
+	// OpeningBalance is the balance the account was opened with, which
+	// reconciliation replays its transactions from.
+	OpeningBalance float64 `pg:",use_zero"`
 	AccountType string
+	Status string
+	StatusReason string
+	// BlockReason is set while a KYC workflow holds the account: dormancy,
+	// a minor attaining majority or missing KYC. Only that workflow clears
+	// it, so staff cannot reactivate the account around it.
+	BlockReason string
+	OpenedAt time.Time
+	DormantSince time.Time
+	OperatingMode string
+	JointApprovalThreshold float64 `pg:",use_zero"`
 	Customer []*Customer `pg:"many2many:customer_to_accounts"`
 	Transaction []*Transaction `pg:"rel:has-many"`
 }
@@ -34,6 +48,14 @@ func (account *Account) Save() (*Account, error) {
 func (account *Account) BeforeInsert (context context.Context) (context.Context,error) {
 
 	account.AccountNumber = uuid.New()
+	if account.Status == "" {
+		account.Status = ACCOUNT_ACTIVE
+	}
+	account.OpenedAt = time.Now()
+	account.OpeningBalance = account.Balance
+	if account.OperatingMode == "" {
+		account.OperatingMode = MODE_EITHER_OR_SURVIVOR
+	}
 	return context,nil
 
 }
@@ -68,21 +90,56 @@ func FindAllAccounts() ([]Account,error) {
 	return accounts,nil
 }
 
-func FindAllAccountsByBranchID(id uint) ([]Account,error) {
+// AccountFilter narrows a list of accounts. Zero fields do not filter.
+type AccountFilter struct {
+	AccountType string
+	Status      string
+}
+
+var accountSortKeys = sortKeys{
+	"id":           "account.id",
+	"balance":      "COALESCE(account.balance, 0)",
+	"account_type": "COALESCE(account.account_type, '')",
+}
+
+func (account *Account) sortValue(key string) interface{} {
+	switch key {
+	case "balance":
+		return account.Balance
+	case "account_type":
+		return account.AccountType
+	}
+	return nil
+}
+
+func FindAllAccountsByBranchID(id uint, filter AccountFilter, opts ListOptions) ([]Account, *Page, error) {
 	var accounts []Account
-	getErr := database.Db.Model(&accounts).
-		Where("branch_id =?",id).
-		Select()
+	query := database.Db.Model(&accounts).
+		Where("account.branch_id = ?", id)
 
-	if getErr != nil {
-		return nil,getErr
+	if filter.AccountType != "" {
+		query = query.Where("account.account_type = ?", filter.AccountType)
+	}
+	if filter.Status != "" {
+		query = query.Where("account.status = ?", filter.Status)
 	}
 
-	
-	return accounts,nil
-}
+	query, err := opts.paginate(query, "account", accountSortKeys)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	getErr := query.Select()
+	if getErr != nil {
+		return nil, nil, getErr
+	}
 
+	page, count := opts.page(len(accounts), func(i int) (interface{}, uint) {
+		return accounts[i].sortValue(opts.Sort), accounts[i].ID
+	})
+
+	return accounts[:count], page, nil
+}
 
 func FindAccountByAccountNumber(accNumber uuid.UUID) (*Account, error) {
 	var account Account
@@ -133,37 +190,30 @@ func DeleteAllAccounts()  error {
 	return nil
 }
 
-func DeleteAccountByID(id uint) (*Account, error) {
-
+func (account *Account) Update() (*Account, error)  {
 	tx, txErr := database.Db.Begin()
 	if txErr != nil {
 		return nil,txErr
 	}
 
-	_, updateErr := tx.Model((*Transaction)(nil)).Set("account_id = NULL").Where("account_id=?",id).Update()
-	if updateErr != nil {
+	current, err := lockAccount(tx, "id = ?", account.ID)
+	if err != nil {
 		tx.Rollback()
-		return nil,updateErr
+		return nil,err
 	}
 
-	var account Account
-	_, deleteErr := tx.Model(&account).Where("id=?",id).Returning("*").Delete(&account)
-	if deleteErr != nil {
+	if current.Status == ACCOUNT_CLOSED {
 		tx.Rollback()
-		return nil,deleteErr
-	}
-
-	tx.Commit()
-	return &account,nil
-}
-
-func (account *Account) Update() (*Account, error)  {
-	tx, txErr := database.Db.Begin()
-	if txErr != nil {
-		return nil,txErr
+		return nil, errors.New("account is closed")
 	}
 
-	updateResult, updateErr := tx.Model(account).WherePK().Returning("*").UpdateNotZero(account)
+	// The status and operating mode only change through ChangeAccountStatus
+	// and SetOperatingMode so that every change is validated and audited.
+	updateResult, updateErr := tx.Model(account).
+		ExcludeColumn("status", "status_reason", "block_reason", "dormant_since", "operating_mode", "joint_approval_threshold", "opening_balance").
+		WherePK().
+		Returning("*").
+		UpdateNotZero(account)
 
 	if updateErr != nil {
 		tx.Rollback()
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	ACCOUNT_ACTIVE        = "active"
+	ACCOUNT_DORMANT       = "dormant"
+	ACCOUNT_DEBIT_FROZEN  = "debit_frozen"
+	ACCOUNT_CREDIT_FROZEN = "credit_frozen"
+	ACCOUNT_TOTAL_FROZEN  = "total_frozen"
+	ACCOUNT_CLOSED        = "closed"
+)
+
+// accountTransitions lists the statuses an account may move to from each status.
+var accountTransitions = map[string][]string{
+	ACCOUNT_ACTIVE:        {ACCOUNT_DORMANT, ACCOUNT_DEBIT_FROZEN, ACCOUNT_CREDIT_FROZEN, ACCOUNT_TOTAL_FROZEN, ACCOUNT_CLOSED},
+	ACCOUNT_DORMANT:       {ACCOUNT_ACTIVE, ACCOUNT_TOTAL_FROZEN, ACCOUNT_CLOSED},
+	ACCOUNT_DEBIT_FROZEN:  {ACCOUNT_ACTIVE, ACCOUNT_TOTAL_FROZEN},
+	ACCOUNT_CREDIT_FROZEN: {ACCOUNT_ACTIVE, ACCOUNT_TOTAL_FROZEN},
+	ACCOUNT_TOTAL_FROZEN:  {ACCOUNT_ACTIVE, ACCOUNT_DEBIT_FROZEN, ACCOUNT_CREDIT_FROZEN},
+	ACCOUNT_CLOSED:        {},
+}
+
+func canTransition(from string, to string) bool {
+	for _, status := range accountTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanDebit reports whether money may leave the account in its current status.
+func (account *Account) CanDebit() error {
+	switch account.Status {
+	case ACCOUNT_ACTIVE, ACCOUNT_CREDIT_FROZEN:
+		return nil
+	case ACCOUNT_DORMANT:
+		return errors.New("account is dormant")
+	case ACCOUNT_CLOSED:
+		return errors.New("account is closed")
+	}
+	return errors.New("account is frozen for debits")
+}
+
+// CanCredit reports whether money may enter the account in its current status.
+func (account *Account) CanCredit() error {
+	switch account.Status {
+	case ACCOUNT_ACTIVE, ACCOUNT_DORMANT, ACCOUNT_DEBIT_FROZEN:
+		return nil
+	case ACCOUNT_CLOSED:
+		return errors.New("account is closed")
+	}
+	return errors.New("account is frozen for credits")
+}
+
+// lockAccount selects the account row for update inside tx so its status
+// and balance cannot change until tx ends.
+func lockAccount(tx *pg.Tx, where string, param interface{}) (*Account, error) {
+	var account Account
+	getErr := tx.Model(&account).
+		Where(where, param).
+		For("UPDATE").
+		Select()
+
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("account does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &account, nil
+}
+
+// kycBlocks are the status reasons that put an account under the block of
+// a KYC workflow.
+var kycBlocks = map[string]bool{
+	MAJORITY_KYC_PENDING: true,
+	KYC_NOT_VERIFIED:     true,
+}
+
+// checkBlock refuses a manual move of a blocked account to a status that
+// allows debits: the block is lifted only by the workflow that placed it.
+func (account *Account) checkBlock(status string) error {
+	if account.BlockReason == "" || (status != ACCOUNT_ACTIVE && status != ACCOUNT_CREDIT_FROZEN) {
+		return nil
+	}
+
+	switch account.BlockReason {
+	case DORMANCY_KYC_PENDING:
+		return errors.New("dormant accounts are reactivated after KYC re-verification")
+	case MAJORITY_KYC_PENDING:
+		return errors.New("accounts of holders who attained majority are unfrozen after fresh KYC")
+	}
+	return errors.New("accounts are unfrozen once the KYC of every holder is verified")
+}
+
+// ChangeAccountStatus moves the account to a new status if the transition is
+// allowed, and audits the change against actor.
+func ChangeAccountStatus(accountID uint, status string, reason string, actor string) (*Account, error) {
+	if status == ACCOUNT_CLOSED {
+		return nil, errors.New("accounts are closed through the closure workflow")
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	current, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := current.checkBlock(status); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	account, err := changeAccountStatus(tx, accountID, status, reason, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return account, nil
+}
+
+func changeAccountStatus(tx *pg.Tx, accountID uint, status string, reason string, actor string) (*Account, error) {
+	if reason == "" {
+		return nil, errors.New("a reason is required to change the account status")
+	}
+
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransition(account.Status, status) {
+		return nil, fmt.Errorf("account cannot move from %s to %s", account.Status, status)
+	}
+
+	if status == ACCOUNT_CLOSED && account.Balance != 0 {
+		return nil, errors.New("account balance must be settled before it is closed")
+	}
+
+	from := account.Status
+	account.Status = status
+	account.StatusReason = reason
+	if status == ACCOUNT_DORMANT {
+		account.DormantSince = time.Now()
+		account.BlockReason = DORMANCY_KYC_PENDING
+	}
+	if kycBlocks[reason] {
+		account.BlockReason = reason
+	}
+
+	_, updateErr := tx.Model(account).
+		Column("status", "status_reason", "block_reason", "dormant_since").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	auditErr := RecordAudit(tx, "account", account.ID, "status_change", actor, map[string]interface{}{
+		"from":   from,
+		"to":     status,
+		"reason": reason,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return account, nil
+}
+
+// freezeDebits stops debits on an account without lifting any freeze it is
+// already under. An active account is frozen for debits; one frozen for
+// credits, or dormant and so able to be reactivated, cannot move there and
+// is frozen totally instead. Accounts already frozen for debits are left
+// as they are. It reports whether the status changed.
+func freezeDebits(tx *pg.Tx, account *Account, reason string, actor string) (bool, error) {
+	status := ACCOUNT_DEBIT_FROZEN
+	switch account.Status {
+	case ACCOUNT_ACTIVE:
+	case ACCOUNT_CREDIT_FROZEN, ACCOUNT_DORMANT:
+		status = ACCOUNT_TOTAL_FROZEN
+	default:
+		return false, nil
+	}
+
+	if _, err := changeAccountStatus(tx, account.ID, status, reason, actor); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseBlock clears the block of an account once the workflow that placed
+// it is complete. An account still in the status the block put it in is
+// made active; one that staff have frozen since keeps its status for them
+// to lift.
+func releaseBlock(tx *pg.Tx, account *Account, reason string, actor string) (*Account, error) {
+	blocked := account.Status == ACCOUNT_DORMANT || account.StatusReason == account.BlockReason
+
+	account.BlockReason = ""
+	_, updateErr := tx.Model(account).
+		Set("block_reason = NULL").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	if !blocked {
+		return account, RecordAudit(tx, "account", account.ID, "block_released", actor, map[string]interface{}{
+			"status": account.Status,
+			"reason": reason,
+		})
+	}
+	return changeAccountStatus(tx, account.ID, ACCOUNT_ACTIVE, reason, actor)
+}
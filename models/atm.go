@@ -0,0 +1,442 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	ATM_IN_SERVICE     = "in_service"
+	ATM_OUT_OF_SERVICE = "out_of_service"
+)
+
+const (
+	DISPENSE_COMPLETED = "dispensed"
+	DISPENSE_REVERSED  = "reversed"
+)
+
+const ATM_MAX_NOTES = 40
+
+const TRANSACTION_REVERSAL = "Reversal"
+
+type ATM struct {
+	ID       uint
+	BranchID uint    `pg:"on_delete:CASCADE"`
+	Branch   *Branch `pg:"rel:has-one"`
+	Location string
+	Status   string
+	Cassette []*Cassette `pg:"rel:has-many"`
+}
+
+type Cassette struct {
+	ID           uint
+	ATMID        uint `pg:"atm_id,on_delete:CASCADE,unique:atm_denomination"`
+	Denomination uint `pg:",unique:atm_denomination"`
+	Count        uint `pg:",use_zero"`
+}
+
+type ATMReplenishment struct {
+	ID           uint
+	ATMID        uint `pg:"atm_id,on_delete:CASCADE"`
+	Denomination uint
+	Count        uint
+	Actor        string
+	Time         time.Time
+}
+
+type ATMDispense struct {
+	ID                    uint
+	ATMID                 uint `pg:"atm_id,on_delete:CASCADE"`
+	CardID                uint
+	TransactionID         uint
+	ReversalTransactionID uint
+	Amount                float64
+	Notes                 map[uint]uint `pg:"type:jsonb"`
+	Status                string
+	Reason                string
+	Time                  time.Time
+}
+
+// ATMReconciliation compares the cash that physically left an ATM with the
+// withdrawals posted against it.
+type ATMReconciliation struct {
+	ATMID             uint
+	Loaded            float64
+	Remaining         float64
+	Dispensed         float64
+	PostedWithdrawals float64
+	PostedReversals   float64
+	Difference        float64
+}
+
+func (atm *ATM) Save() (*ATM, error) {
+	atm.Status = ATM_IN_SERVICE
+
+	_, insertErr := database.Db.Model(atm).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return atm, nil
+}
+
+func FindATMByID(id uint) (*ATM, error) {
+	var atm ATM
+	getErr := database.Db.Model(&atm).
+		Relation("Cassette").
+		Where("atm.id = ?", id).
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &atm, nil
+}
+
+func FindAllATMsByBranchID(id uint) ([]ATM, error) {
+	var atms []ATM
+	getErr := database.Db.Model(&atms).
+		Relation("Cassette").
+		Where("branch_id = ?", id).
+		Order("atm.id").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return atms, nil
+}
+
+// dispensePlan picks the fewest notes from the cassettes that add up to
+// amount exactly, or returns nil if the amount cannot be dispensed.
+func dispensePlan(cassettes []*Cassette, amount uint) map[uint]uint {
+	sort.Slice(cassettes, func(i, j int) bool {
+		return cassettes[i].Denomination > cassettes[j].Denomination
+	})
+
+	const unreachable = ATM_MAX_NOTES + 1
+
+	// best[v] is the fewest notes making v using the cassettes seen so far;
+	// used[i][v] is how many notes of cassette i that solution takes.
+	best := make([]uint, amount+1)
+	for v := range best {
+		best[v] = unreachable
+	}
+	best[0] = 0
+
+	used := make([][]uint, len(cassettes))
+	for i, cassette := range cassettes {
+		used[i] = make([]uint, amount+1)
+		if cassette.Denomination == 0 {
+			continue
+		}
+
+		next := make([]uint, amount+1)
+		copy(next, best)
+		for v := uint(0); v <= amount; v++ {
+			for k := uint(1); k <= cassette.Count && k*cassette.Denomination <= v; k++ {
+				previous := best[v-k*cassette.Denomination]
+				if previous+k < next[v] {
+					next[v] = previous + k
+					used[i][v] = k
+				}
+			}
+		}
+		best = next
+	}
+
+	if best[amount] > ATM_MAX_NOTES {
+		return nil
+	}
+
+	plan := map[uint]uint{}
+	remaining := amount
+	for i := len(cassettes) - 1; i >= 0; i-- {
+		k := used[i][remaining]
+		if k > 0 {
+			plan[cassettes[i].Denomination] = k
+			remaining -= k * cassettes[i].Denomination
+		}
+	}
+
+	return plan
+}
+
+// ATMWithdrawal verifies the card and PIN, works out which notes to dispense,
+// debits the account and takes the notes out of the cassettes. When
+// dispenseFails is set the simulated hardware fails to hand out the cash and
+// the withdrawal is reversed straight away.
+func ATMWithdrawal(atmID uint, number string, pin string, amount uint, dispenseFails bool) (*ATMDispense, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+	// The cassettes are locked, so even a panic must end the transaction.
+	defer tx.Rollback()
+
+	dispense, err := atmWithdrawal(tx, atmID, number, pin, amount)
+	if err == ErrIncorrectPIN {
+		tx.Commit()
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Commit()
+
+	if dispenseFails {
+		return ReverseATMDispense(dispense.ID, "cash dispense failed")
+	}
+
+	return dispense, nil
+}
+
+func atmWithdrawal(tx *pg.Tx, atmID uint, number string, pin string, amount uint) (*ATMDispense, error) {
+	var atm ATM
+	getErr := tx.Model(&atm).Where("id = ?", atmID).Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if atm.Status != ATM_IN_SERVICE {
+		return nil, errors.New("ATM is out of service")
+	}
+
+	var cassettes []*Cassette
+	getErr = tx.Model(&cassettes).
+		Where("atm_id = ?", atm.ID).
+		For("UPDATE").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	// The planner's work grows with the amount, so amounts no full load of
+	// notes could make up are refused first, and the rest only once the
+	// card has been checked against its ATM limit.
+	var largest uint
+	for _, cassette := range cassettes {
+		if cassette.Count > 0 && cassette.Denomination > largest {
+			largest = cassette.Denomination
+		}
+	}
+	if amount == 0 || amount > ATM_MAX_NOTES*largest {
+		return nil, errors.New("amount cannot be dispensed with the notes available")
+	}
+
+	authorization, err := authorizeCard(tx, number, pin, float64(amount), CHANNEL_ATM, fmt.Sprintf("ATM-%d", atm.ID), true)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := dispensePlan(cassettes, amount)
+	if plan == nil {
+		return nil, errors.New("amount cannot be dispensed with the notes available")
+	}
+
+	for _, cassette := range cassettes {
+		if plan[cassette.Denomination] == 0 {
+			continue
+		}
+
+		_, updateErr := tx.Model(cassette).
+			Set("count = count - ?", plan[cassette.Denomination]).
+			WherePK().
+			Update()
+		if updateErr != nil {
+			return nil, updateErr
+		}
+	}
+
+	card, err := lockCard(tx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	dispense := ATMDispense{
+		ATMID:         atm.ID,
+		CardID:        card.ID,
+		TransactionID: authorization.Transaction.ID,
+		Amount:        float64(amount),
+		Notes:         plan,
+		Status:        DISPENSE_COMPLETED,
+		Time:          time.Now(),
+	}
+
+	_, insertErr := tx.Model(&dispense).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &dispense, nil
+}
+
+// ReverseATMDispense undoes a withdrawal whose cash was never handed out:
+// the notes go back to the cassettes and the account is credited, provided
+// its status still allows credits.
+func ReverseATMDispense(id uint, reason string) (*ATMDispense, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	var dispense ATMDispense
+	getErr := tx.Model(&dispense).
+		Where("id = ?", id).
+		For("UPDATE").
+		Select()
+	if getErr != nil {
+		tx.Rollback()
+		return nil, getErr
+	}
+
+	if dispense.Status != DISPENSE_COMPLETED {
+		tx.Rollback()
+		return nil, errors.New("dispense has already been reversed")
+	}
+
+	var original Transaction
+	getErr = tx.Model(&original).Where("id = ?", dispense.TransactionID).Select()
+	if getErr != nil {
+		tx.Rollback()
+		return nil, getErr
+	}
+
+	for denomination, count := range dispense.Notes {
+		_, updateErr := tx.Model((*Cassette)(nil)).
+			Set("count = count + ?", count).
+			Where("atm_id = ?", dispense.ATMID).
+			Where("denomination = ?", denomination).
+			Update()
+		if updateErr != nil {
+			tx.Rollback()
+			return nil, updateErr
+		}
+	}
+
+	account, err := lockAccount(tx, "id = ?", original.AccountID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := accountCredit(tx, account, original.Amount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	reversal := Transaction{
+		AccountID:         account.ID,
+		ModeOfPayment:     CHANNEL_ATM,
+		TypeOfTransaction: TRANSACTION_REVERSAL,
+		Amount:            original.Amount,
+		Reference:         original.Reference,
+	}
+	if err := recordTransaction(tx, &reversal); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	dispense.Status = DISPENSE_REVERSED
+	dispense.Reason = reason
+	dispense.ReversalTransactionID = reversal.ID
+	_, updateErr := tx.Model(&dispense).WherePK().Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+
+	tx.Commit()
+	return &dispense, nil
+}
+
+// ReplenishATM loads notes into an ATM's cassettes, adding a cassette for any
+// new denomination.
+func ReplenishATM(atmID uint, notes map[uint]uint, actor string) (*ATM, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	now := time.Now()
+	for denomination, count := range notes {
+		if denomination == 0 || count == 0 {
+			continue
+		}
+
+		cassette := Cassette{ATMID: atmID, Denomination: denomination, Count: count}
+		_, insertErr := tx.Model(&cassette).
+			OnConflict("(atm_id, denomination) DO UPDATE").
+			Set("count = cassette.count + EXCLUDED.count").
+			Insert()
+		if insertErr != nil {
+			tx.Rollback()
+			return nil, insertErr
+		}
+
+		replenishment := ATMReplenishment{
+			ATMID:        atmID,
+			Denomination: denomination,
+			Count:        count,
+			Actor:        actor,
+			Time:         now,
+		}
+		_, insertErr = tx.Model(&replenishment).Insert()
+		if insertErr != nil {
+			tx.Rollback()
+			return nil, insertErr
+		}
+	}
+
+	tx.Commit()
+	return FindATMByID(atmID)
+}
+
+func ReconcileATM(atmID uint) (*ATMReconciliation, error) {
+	reconciliation := ATMReconciliation{ATMID: atmID}
+
+	err := database.Db.Model((*ATMReplenishment)(nil)).
+		ColumnExpr("coalesce(sum(denomination * count), 0)").
+		Where("atm_id = ?", atmID).
+		Select(pg.Scan(&reconciliation.Loaded))
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Db.Model((*Cassette)(nil)).
+		ColumnExpr("coalesce(sum(denomination * count), 0)").
+		Where("atm_id = ?", atmID).
+		Select(pg.Scan(&reconciliation.Remaining))
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Db.Model((*Transaction)(nil)).
+		ColumnExpr("coalesce(sum(amount), 0)").
+		Where("id IN (SELECT transaction_id FROM atm_dispenses WHERE atm_id = ?)", atmID).
+		Select(pg.Scan(&reconciliation.PostedWithdrawals))
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Db.Model((*Transaction)(nil)).
+		ColumnExpr("coalesce(sum(amount), 0)").
+		Where("id IN (SELECT reversal_transaction_id FROM atm_dispenses WHERE atm_id = ?)", atmID).
+		Select(pg.Scan(&reconciliation.PostedReversals))
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation.Dispensed = reconciliation.Loaded - reconciliation.Remaining
+	reconciliation.Difference = reconciliation.Dispensed - (reconciliation.PostedWithdrawals - reconciliation.PostedReversals)
+
+	return &reconciliation, nil
+}
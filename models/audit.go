@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+type AuditLog struct {
+	ID       uint
+	Entity   string
+	EntityID uint
+	Action   string
+	Actor    string
+	Details  map[string]interface{} `pg:"type:jsonb"`
+	Time     time.Time
+}
+
+// RecordAudit writes an audit entry through db, which may be a transaction
+// so the entry commits or rolls back together with the change it describes.
+func RecordAudit(db orm.DB, entity string, entityID uint, action string, actor string, details map[string]interface{}) error {
+	entry := AuditLog{
+		Entity:   entity,
+		EntityID: entityID,
+		Action:   action,
+		Actor:    actor,
+		Details:  details,
+		Time:     time.Now(),
+	}
+
+	_, insertErr := db.Model(&entry).Insert()
+	return insertErr
+}
+
+func FindAllAuditLogsByEntity(entity string, id uint) ([]AuditLog, error) {
+	var logs []AuditLog
+	getErr := database.Db.Model(&logs).
+		Where("entity = ?", entity).
+		Where("entity_id = ?", id).
+		Order("id").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return logs, nil
+}
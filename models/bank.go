@@ -24,18 +24,35 @@ func (bank *Bank) Save() (*Bank, error) {
 	return bank, nil
 }
 
-func FindAllBanks() ([]Bank,error) {
-	var banks []Bank
-	getErr := database.Db.Model(&banks).
-		Select()
+var bankSortKeys = sortKeys{
+	"id":   "bank.id",
+	"name": "COALESCE(bank.name, '')",
+}
+
+func (bank *Bank) sortValue(key string) interface{} {
+	if key == "name" {
+		return bank.Name
+	}
+	return nil
+}
 
+func FindAllBanks(opts ListOptions) ([]Bank, *Page, error) {
+	var banks []Bank
+	query, err := opts.paginate(database.Db.Model(&banks), "bank", bankSortKeys)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	getErr := query.Select()
 	if getErr != nil {
-		return nil,getErr
+		return nil, nil, getErr
 	}
 
-	
-	return banks,nil
+	page, count := opts.page(len(banks), func(i int) (interface{}, uint) {
+		return banks[i].sortValue(opts.Sort), banks[i].ID
+	})
+
+	return banks[:count], page, nil
 }
 
 func FindBankByID(id uint) (*Bank, error){
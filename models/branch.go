@@ -64,17 +64,35 @@ func FindBranchByID(id uint) (*Branch, error){
 	return &output,nil
 }
 
-func FindAllBranchesByBankID(id uint) ([]Branch,error) {
+var branchSortKeys = sortKeys{
+	"id":      "branch.id",
+	"address": "COALESCE(branch.address, '')",
+}
+
+func (branch *Branch) sortValue(key string) interface{} {
+	if key == "address" {
+		return branch.Address
+	}
+	return nil
+}
+
+func FindAllBranchesByBankID(id uint, opts ListOptions) ([]Branch, *Page, error) {
 	var branches []Branch
-	getErr := database.Db.Model(&branches).
-		Where("bank_id =?",id).
-		Select()
+	query, err := opts.paginate(database.Db.Model(&branches).Where("branch.bank_id = ?", id), "branch", branchSortKeys)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	getErr := query.Select()
 	if getErr != nil {
-		return nil,getErr
+		return nil, nil, getErr
 	}
 
-	return branches,nil
+	page, count := opts.page(len(branches), func(i int) (interface{}, uint) {
+		return branches[i].sortValue(opts.Sort), branches[i].ID
+	})
+
+	return branches[:count], page, nil
 }
 
 func DeleteAllBranches()  error {
@@ -0,0 +1,666 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	DAY_OPEN   = "open"
+	DAY_EOD    = "eod"
+	DAY_CLOSED = "closed"
+)
+
+const (
+	EOD_RUNNING   = "running"
+	EOD_FAILED    = "failed"
+	EOD_COMPLETED = "completed"
+)
+
+// EOD_LOCK_CLASS namespaces the advisory locks taken while the end of day
+// of a bank runs, so that only one run per bank can be in progress.
+const EOD_LOCK_CLASS = 43
+
+var ErrBackDatedPosting = errors.New("value date falls on a closed business day; it must be posted with a supervisor's approval")
+
+// BusinessDay is the day a bank is booking postings to. Once end of day has
+// started, new postings are booked to the following day until the next
+// business day is opened.
+type BusinessDay struct {
+	ID       uint
+	BankID   uint      `pg:"on_delete:CASCADE,unique"`
+	Date     time.Time `pg:"type:date"`
+	Status   string
+	ClosedAt time.Time
+}
+
+// EODRun is the end of day of one bank for one business date. Every step
+// that completes is checkpointed, so a failed run resumes where it stopped.
+type EODRun struct {
+	ID           uint
+	BankID       uint      `pg:"on_delete:CASCADE,unique:bank_date"`
+	BusinessDate time.Time `pg:"type:date,unique:bank_date"`
+	Status       string
+	Error        string
+	StartedBy    string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	Steps        []*EODStep `pg:"rel:has-many"`
+}
+
+type EODStep struct {
+	ID          uint
+	EODRunID    uint   `pg:"eod_run_id,on_delete:CASCADE,unique:run_step"`
+	Name        string `pg:",unique:run_step"`
+	CompletedAt time.Time
+}
+
+// InterestAccrual is the interest one account earned on one business day.
+// Accruals are memo entries; interest is posted when it is credited.
+type InterestAccrual struct {
+	ID           uint
+	AccountID    uint      `pg:"on_delete:CASCADE,unique:account_date"`
+	BusinessDate time.Time `pg:"type:date,unique:account_date"`
+	Balance      float64
+	Amount       float64
+}
+
+// DayEndReport summarises the postings a bank booked on a business day.
+type DayEndReport struct {
+	ID               uint
+	BankID           uint      `pg:"on_delete:CASCADE,unique:bank_date"`
+	BusinessDate     time.Time `pg:"type:date,unique:bank_date"`
+	Postings         int       `pg:",use_zero"`
+	TotalDeposits    float64   `pg:",use_zero"`
+	TotalWithdrawals float64   `pg:",use_zero"`
+	TotalTransfers   float64   `pg:",use_zero"`
+	InterestAccrued  float64   `pg:",use_zero"`
+	AccountsOpened   int       `pg:",use_zero"`
+	BackDated        int       `pg:",use_zero"`
+	GeneratedAt      time.Time
+}
+
+type eodStep struct {
+	name string
+	run  func(tx *pg.Tx, bankID uint, date time.Time) error
+}
+
+// eodSteps run in order. A step and its checkpoint commit together; steps
+// that call into other subsystems commit on their own and must be safe to
+// repeat.
+var eodSteps = []eodStep{
+	{"interest accrual", accrueInterest},
+	{"standing instructions", runStandingInstructions},
+	{"dormancy", runDormancy},
+	{"report generation", generateDayEndReport},
+	{"dashboard refresh", refreshDashboards},
+}
+
+// dateOf drops the time of day, keeping the calendar date t falls on.
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// currentBusinessDay returns the business day of a bank, starting it on
+// today's date the first time it is asked for.
+func currentBusinessDay(db orm.DB, bankID uint, forUpdate bool) (*BusinessDay, error) {
+	var day BusinessDay
+	query := db.Model(&day).Where("bank_id = ?", bankID)
+	if forUpdate {
+		query = query.For("UPDATE")
+	}
+
+	getErr := query.Select()
+	if getErr == pg.ErrNoRows {
+		_, insertErr := db.Model(&BusinessDay{BankID: bankID, Date: dateOf(time.Now()), Status: DAY_OPEN}).
+			OnConflict("(bank_id) DO NOTHING").
+			Insert()
+		if insertErr != nil {
+			return nil, insertErr
+		}
+		getErr = query.Select()
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &day, nil
+}
+
+// bookingDate is the date new postings are booked to: the business date
+// while the day is open and the following day once end of day has started.
+func (day *BusinessDay) bookingDate() time.Time {
+	if day.Status == DAY_OPEN {
+		return day.Date
+	}
+	return day.Date.AddDate(0, 0, 1)
+}
+
+func FindBusinessDayByBankID(bankID uint) (*BusinessDay, error) {
+	if _, err := FindBankByID(bankID); err != nil {
+		return nil, err
+	}
+	return currentBusinessDay(database.Db, bankID, false)
+}
+
+// stampPostingDates books a posting to the business date of its bank and
+// defaults its value date to the booking date. A value date before the
+// booking date falls on a day that has been closed and needs an override.
+func stampPostingDates(db orm.DB, transaction *Transaction) error {
+	if !transaction.BookingDate.IsZero() {
+		return nil
+	}
+
+	var bankID uint
+	_, err := db.QueryOne(pg.Scan(&bankID), `
+		SELECT b.bank_id FROM accounts a JOIN branches b ON b.id = a.branch_id
+		WHERE a.id = ?`, transaction.AccountID)
+	if err == pg.ErrNoRows {
+		return errors.New("account does not exist")
+	}
+	if err != nil {
+		return err
+	}
+
+	day, err := currentBusinessDay(db, bankID, false)
+	if err != nil {
+		return err
+	}
+
+	booking := day.bookingDate()
+	value := booking
+	if !transaction.ValueDate.IsZero() {
+		value = dateOf(transaction.ValueDate)
+	}
+
+	if value.After(booking) {
+		return errors.New("value date cannot be after the booking date")
+	}
+	if value.Before(booking) && transaction.OverrideBy == "" {
+		return ErrBackDatedPosting
+	}
+
+	transaction.BookingDate = booking
+	transaction.ValueDate = value
+	return nil
+}
+
+// StampPostingDates checks and sets the value and booking dates of a
+// posting before any money moves.
+func StampPostingDates(transaction *Transaction) error {
+	return stampPostingDates(database.Db, transaction)
+}
+
+// PostBackDated books a deposit, withdrawal or transfer whose value date
+// falls on a business day that has already been closed. supervisorID must
+// be an active supervisor of the account's bank, who is recorded as the
+// override on the posting. Debits still go through the checks of the
+// shared debit path, initiated by transaction.InitiatedBy.
+func PostBackDated(transaction *Transaction, supervisorID uint) (*Transaction, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	err := postBackDated(tx, transaction, supervisorID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return transaction, nil
+}
+
+func postBackDated(tx *pg.Tx, transaction *Transaction, supervisorID uint) error {
+	supervisor, err := findSupervisor(tx, supervisorID, transaction.AccountID)
+	if err != nil {
+		return err
+	}
+
+	transaction.OverrideBy = supervisor.actor()
+	if transaction.Time.IsZero() {
+		transaction.Time = time.Now()
+	}
+	if err := stampPostingDates(tx, transaction); err != nil {
+		return err
+	}
+	if !transaction.ValueDate.Before(transaction.BookingDate) {
+		return errors.New("value date is not before the booking date; post it as usual")
+	}
+
+	switch transaction.TypeOfTransaction {
+	case TRANSACTION_DEPOSIT:
+		account, err := lockAccount(tx, "id = ?", transaction.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := accountCredit(tx, account, transaction.Amount); err != nil {
+			return err
+		}
+	case TRANSACTION_WITHDRAW:
+		if err := accountWithdrawal(tx, transaction.AccountID, transaction.Amount, transaction.InitiatedBy); err != nil {
+			return err
+		}
+	case TRANSACTION_TRANSFER:
+		if err := accountWithdrawal(tx, transaction.AccountID, transaction.Amount, transaction.InitiatedBy); err != nil {
+			return err
+		}
+		receiver, err := lockAccount(tx, "account_number = ?", transaction.ReceiverAccountNumber)
+		if err != nil {
+			return err
+		}
+		if err := accountCredit(tx, receiver, transaction.Amount); err != nil {
+			return err
+		}
+	default:
+		return errors.New("only deposits, withdrawals and transfers can be back-dated")
+	}
+
+	return recordTransaction(tx, transaction)
+}
+
+// auditOverride records who allowed a back-dated posting once it is saved.
+func auditOverride(db orm.DB, transaction *Transaction) error {
+	if transaction.OverrideBy == "" || !transaction.ValueDate.Before(transaction.BookingDate) {
+		return nil
+	}
+
+	return RecordAudit(db, "account", transaction.AccountID, "backdated_posting", transaction.OverrideBy, map[string]interface{}{
+		"transaction_id": transaction.ID,
+		"value_date":     transaction.ValueDate.Format("2006-01-02"),
+		"booking_date":   transaction.BookingDate.Format("2006-01-02"),
+	})
+}
+
+// inBank narrows query to rows whose account, named by column, belongs to a
+// branch of the bank. A bankID of 0 leaves query as it is, for the
+// background jobs that work across every bank.
+func inBank(query *orm.Query, column string, bankID uint) *orm.Query {
+	if bankID == 0 {
+		return query
+	}
+	return query.Where(column+" IN (SELECT a.id FROM accounts a JOIN branches b ON b.id = a.branch_id WHERE b.bank_id = ?)", bankID)
+}
+
+func accrueInterest(tx *pg.Tx, bankID uint, date time.Time) error {
+	_, err := tx.Exec(`
+		INSERT INTO interest_accruals (account_id, business_date, balance, amount)
+		SELECT a.id, ?::date, a.balance, round((a.balance * ? / 365)::numeric, 4)::float8
+		FROM accounts a JOIN branches b ON b.id = a.branch_id
+		WHERE b.bank_id = ? AND a.balance > 0 AND a.status IS DISTINCT FROM ?
+		ON CONFLICT (account_id, business_date) DO NOTHING`,
+		date, SAVINGS_INTEREST_RATE, bankID, ACCOUNT_CLOSED)
+	return err
+}
+
+// runStandingInstructions carries out the instructions the bank acts on by
+// itself: sweep rules of the bank's accounts, and the expiry of the payment
+// requests and instructions against them that nobody acted on.
+func runStandingInstructions(tx *pg.Tx, bankID uint, date time.Time) error {
+	var accountIDs []uint
+	getErr := tx.Model((*SweepRule)(nil)).
+		Column("sweep_rule.account_id").
+		Join("JOIN accounts a ON a.id = sweep_rule.account_id").
+		Join("JOIN branches b ON b.id = a.branch_id").
+		Where("sweep_rule.active").
+		Where("b.bank_id = ?", bankID).
+		Select(&accountIDs)
+	if getErr != nil {
+		return getErr
+	}
+
+	// A rule that fails is logged and left for the next run, as in
+	// RunAllSweeps, so that it cannot hold up the close of the day.
+	for _, id := range accountIDs {
+		if _, err := RunSweep(id); err != nil {
+			log.Printf("sweep of account %d failed during EOD of bank %d: %s\n", id, bankID, err.Error())
+		}
+	}
+
+	if err := expirePendingInstructions(bankID); err != nil {
+		return err
+	}
+	return expireCollectRequests(bankID)
+}
+
+// runDormancy marks the bank's inactive accounts dormant and registers its
+// long-dormant ones as unclaimed.
+func runDormancy(tx *pg.Tx, bankID uint, date time.Time) error {
+	if err := markDormantAccounts(bankID); err != nil {
+		return err
+	}
+	return flagUnclaimedDeposits(bankID)
+}
+
+func generateDayEndReport(tx *pg.Tx, bankID uint, date time.Time) error {
+	report := DayEndReport{
+		BankID:       bankID,
+		BusinessDate: date,
+		GeneratedAt:  time.Now(),
+	}
+
+	_, err := tx.QueryOne(&report, `
+		SELECT count(*) AS postings,
+			COALESCE(sum(t.amount) FILTER (WHERE t.type_of_transaction = ?), 0) AS total_deposits,
+			COALESCE(sum(t.amount) FILTER (WHERE t.type_of_transaction = ?), 0) AS total_withdrawals,
+			COALESCE(sum(t.amount) FILTER (WHERE t.type_of_transaction = ?), 0) AS total_transfers,
+			count(*) FILTER (WHERE t.value_date < t.booking_date) AS back_dated
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		JOIN branches b ON b.id = a.branch_id
+		WHERE b.bank_id = ? AND t.booking_date = ?`,
+		TRANSACTION_DEPOSIT, TRANSACTION_WITHDRAW, TRANSACTION_TRANSFER, bankID, date)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.QueryOne(&report, `
+		SELECT
+			(SELECT COALESCE(sum(i.amount), 0) FROM interest_accruals i
+			 JOIN accounts a ON a.id = i.account_id JOIN branches b ON b.id = a.branch_id
+			 WHERE b.bank_id = ?0 AND i.business_date = ?1) AS interest_accrued,
+			(SELECT count(*) FROM accounts a JOIN branches b ON b.id = a.branch_id
+			 WHERE b.bank_id = ?0 AND a.opened_at::date = ?1) AS accounts_opened`,
+		bankID, date)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Model(&report).
+		OnConflict("(bank_id, business_date) DO UPDATE").
+		Set("postings = EXCLUDED.postings").
+		Set("total_deposits = EXCLUDED.total_deposits").
+		Set("total_withdrawals = EXCLUDED.total_withdrawals").
+		Set("total_transfers = EXCLUDED.total_transfers").
+		Set("interest_accrued = EXCLUDED.interest_accrued").
+		Set("accounts_opened = EXCLUDED.accounts_opened").
+		Set("back_dated = EXCLUDED.back_dated").
+		Set("generated_at = EXCLUDED.generated_at").
+		Insert()
+	return err
+}
+
+// refreshDashboards refreshes the MIS views, which cover every bank; the
+// refresh is cheap enough to repeat for each bank's EOD.
+func refreshDashboards(tx *pg.Tx, bankID uint, date time.Time) error {
+	return RefreshMISViews()
+}
+
+// RunEOD closes the current business day of a bank. New postings are booked
+// to the next day from the moment it starts. If a step fails the run is
+// marked failed and can be started again; completed steps are skipped.
+func RunEOD(bankID uint, actor string) (*EODRun, error) {
+	if _, err := FindBankByID(bankID); err != nil {
+		return nil, err
+	}
+
+	// The advisory lock is held by lockTx for the whole run and released
+	// when it ends, or when the connection drops if the process dies.
+	lockTx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+	defer lockTx.Rollback()
+
+	var locked bool
+	_, lockErr := lockTx.QueryOne(pg.Scan(&locked), "SELECT pg_try_advisory_xact_lock(?, ?)", EOD_LOCK_CLASS, bankID)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	if !locked {
+		return nil, errors.New("end of day is already running for this bank")
+	}
+
+	run, err := startEODRun(bankID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	done := map[string]bool{}
+	for _, step := range run.Steps {
+		done[step.Name] = true
+	}
+
+	for _, step := range eodSteps {
+		if done[step.name] {
+			continue
+		}
+
+		if err := runEODStep(run, step); err != nil {
+			run.Status = EOD_FAILED
+			run.Error = fmt.Sprintf("%s: %s", step.name, err.Error())
+			database.Db.Model(run).Column("status", "error").WherePK().Update()
+			return run, err
+		}
+	}
+
+	err = finishEODRun(run, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindEODRunByID(run.ID)
+}
+
+// startEODRun marks the business day as closing and returns its run,
+// creating it on the first attempt.
+func startEODRun(bankID uint, actor string) (*EODRun, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	run, err := startEODRunTx(tx, bankID, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return run, nil
+}
+
+func startEODRunTx(tx *pg.Tx, bankID uint, actor string) (*EODRun, error) {
+	day, err := currentBusinessDay(tx, bankID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if day.Status == DAY_CLOSED {
+		return nil, errors.New("business day is already closed; start the next business day first")
+	}
+
+	if day.Status == DAY_OPEN {
+		day.Status = DAY_EOD
+		_, updateErr := tx.Model(day).Column("status").WherePK().Update()
+		if updateErr != nil {
+			return nil, updateErr
+		}
+	}
+
+	run := EODRun{
+		BankID:       bankID,
+		BusinessDate: day.Date,
+		Status:       EOD_RUNNING,
+		StartedBy:    actor,
+		StartedAt:    time.Now(),
+	}
+	_, insertErr := tx.Model(&run).
+		OnConflict("(bank_id, business_date) DO UPDATE").
+		Set("status = EXCLUDED.status").
+		Set("error = NULL").
+		Returning("*").
+		Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	getErr := tx.Model(&run.Steps).Where("eod_run_id = ?", run.ID).Order("id").Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	auditErr := RecordAudit(tx, "bank", bankID, "eod_started", actor, map[string]interface{}{
+		"business_date": day.Date.Format("2006-01-02"),
+		"run_id":        run.ID,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return &run, nil
+}
+
+func runEODStep(run *EODRun, step eodStep) error {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	err := step.run(tx, run.BankID, dateOf(run.BusinessDate))
+	if err == nil {
+		_, err = tx.Model(&EODStep{EODRunID: run.ID, Name: step.name, CompletedAt: time.Now()}).Insert()
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func finishEODRun(run *EODRun, actor string) error {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	now := time.Now()
+	_, err := tx.Model(run).
+		Set("status = ?", EOD_COMPLETED).
+		Set("finished_at = ?", now).
+		WherePK().
+		Update()
+	if err == nil {
+		_, err = tx.Model((*BusinessDay)(nil)).
+			Set("status = ?", DAY_CLOSED).
+			Set("closed_at = ?", now).
+			Where("bank_id = ?", run.BankID).
+			Update()
+	}
+	if err == nil {
+		err = RecordAudit(tx, "bank", run.BankID, "eod_completed", actor, map[string]interface{}{
+			"business_date": dateOf(run.BusinessDate).Format("2006-01-02"),
+			"run_id":        run.ID,
+		})
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// StartBusinessDay opens the day after a closed business day.
+func StartBusinessDay(bankID uint, actor string) (*BusinessDay, error) {
+	if _, err := FindBankByID(bankID); err != nil {
+		return nil, err
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	day, err := startBusinessDay(tx, bankID, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return day, nil
+}
+
+func startBusinessDay(tx *pg.Tx, bankID uint, actor string) (*BusinessDay, error) {
+	day, err := currentBusinessDay(tx, bankID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if day.Status != DAY_CLOSED {
+		return nil, errors.New("business day has not been closed by end of day")
+	}
+
+	day.Date = day.Date.AddDate(0, 0, 1)
+	day.Status = DAY_OPEN
+	_, updateErr := tx.Model(day).Column("date", "status").WherePK().Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	auditErr := RecordAudit(tx, "bank", bankID, "bod", actor, map[string]interface{}{
+		"business_date": day.Date.Format("2006-01-02"),
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return day, nil
+}
+
+func FindEODRunByID(id uint) (*EODRun, error) {
+	var run EODRun
+	getErr := database.Db.Model(&run).
+		Relation("Steps", func(q *pg.Query) (*pg.Query, error) {
+			return q.Order("eod_step.id"), nil
+		}).
+		Where("eod_run.id = ?", id).
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &run, nil
+}
+
+func FindAllEODRunsByBankID(bankID uint) ([]EODRun, error) {
+	var runs []EODRun
+	getErr := database.Db.Model(&runs).
+		Relation("Steps", func(q *pg.Query) (*pg.Query, error) {
+			return q.Order("eod_step.id"), nil
+		}).
+		Where("eod_run.bank_id = ?", bankID).
+		Order("eod_run.business_date DESC").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return runs, nil
+}
+
+func FindDayEndReport(bankID uint, date time.Time) (*DayEndReport, error) {
+	var report DayEndReport
+	getErr := database.Db.Model(&report).
+		Where("bank_id = ?", bankID).
+		Where("business_date = ?", dateOf(date)).
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("no day-end report for this date")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &report, nil
+}
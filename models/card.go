@@ -0,0 +1,680 @@
+package models
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	CARD_ACTIVE    = "active"
+	CARD_BLOCKED   = "blocked"
+	CARD_HOTLISTED = "hotlisted"
+)
+
+// Why a card is blocked. A customer may lift their own block with the PIN,
+// but only staff may lift one placed after MAX_PIN_ATTEMPTS wrong PINs.
+const (
+	CARD_BLOCK_CUSTOMER    = "customer"
+	CARD_BLOCK_PIN_LOCKOUT = "pin_lockout"
+)
+
+const (
+	CHANNEL_ATM = "ATM"
+	CHANNEL_POS = "POS"
+)
+
+const (
+	CARD_BIN            = "652100"
+	CARD_VALIDITY_YEARS = 5
+	DEFAULT_ATM_LIMIT   = 25000.00
+	DEFAULT_POS_LIMIT   = 100000.00
+	MAX_PIN_ATTEMPTS    = 3
+	CARD_HOLD_VALIDITY  = 7 * 24 * time.Hour
+)
+
+var ErrIncorrectPIN = errors.New("incorrect PIN")
+
+type Card struct {
+	ID        uint
+	AccountID uint     `pg:"on_delete:CASCADE"`
+	Account   *Account `pg:"rel:has-one"`
+	// CustomerID is the holder, or the guardian of a minor's account, the
+	// card was issued to. Its debits are theirs.
+	CustomerID        uint      `pg:"on_delete:RESTRICT"`
+	Customer          *Customer `pg:"rel:has-one"`
+	Number            string    `pg:",unique"`
+	Expiry            time.Time
+	PINHash           string `json:"-"`
+	Status            string
+	BlockReason       string
+	ATMLimit          float64
+	POSLimit          float64
+	FailedPINAttempts uint
+	IssuedAt          time.Time
+}
+
+// CardAuthorization is the outcome of a card-present authorization.
+type CardAuthorization struct {
+	CardNumber  string
+	Channel     string
+	TerminalID  string
+	Amount      float64
+	Hold        *Hold
+	Transaction *Transaction
+}
+
+// luhnCheckDigit returns the digit that makes payload followed by it pass
+// the Luhn check.
+func luhnCheckDigit(payload string) byte {
+	sum := 0
+	double := true
+	for i := len(payload) - 1; i >= 0; i-- {
+		digit := int(payload[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func validLuhn(number string) bool {
+	if len(number) < 2 {
+		return false
+	}
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return luhnCheckDigit(number[:len(number)-1]) == number[len(number)-1]
+}
+
+func newCardNumber() (string, error) {
+	payload := CARD_BIN
+	for len(payload) < 15 {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		payload += digit.String()
+	}
+	return payload + string(luhnCheckDigit(payload)), nil
+}
+
+func validPIN(pin string) error {
+	if len(pin) != 4 {
+		return errors.New("PIN must be 4 digits")
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return errors.New("PIN must be 4 digits")
+		}
+	}
+	return nil
+}
+
+func hashPIN(pin string) (string, error) {
+	if err := validPIN(pin); err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// MaskCardNumber keeps only the first six and last four digits.
+func MaskCardNumber(number string) string {
+	if len(number) < 10 {
+		return number
+	}
+	masked := []byte(number)
+	for i := 6; i < len(masked)-4; i++ {
+		masked[i] = 'X'
+	}
+	return string(masked)
+}
+
+// reference is written on the transactions and holds created by the card
+// so its daily usage can be totalled.
+func (card *Card) reference() string {
+	return fmt.Sprintf("CARD-%d", card.ID)
+}
+
+// IssueCard issues a debit card on an account to one of its holders, or to
+// the guardian of a minor's account, with the given PIN and limits. Zero
+// limits fall back to the defaults.
+func IssueCard(accountID uint, customerID uint, pin string, atmLimit float64, posLimit float64) (*Card, error) {
+	account, err := FindAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkOperator(database.Db, account.ID, customerID); err != nil {
+		return nil, err
+	}
+
+	if err := account.CanDebit(); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPIN(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := newCardNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	if atmLimit == 0 {
+		atmLimit = DEFAULT_ATM_LIMIT
+	}
+	if posLimit == 0 {
+		posLimit = DEFAULT_POS_LIMIT
+	}
+
+	now := time.Now()
+	card := Card{
+		AccountID:  account.ID,
+		CustomerID: customerID,
+		Number:     number,
+		Expiry:     now.AddDate(CARD_VALIDITY_YEARS, 0, 0),
+		PINHash:    hash,
+		Status:     CARD_ACTIVE,
+		ATMLimit:   atmLimit,
+		POSLimit:   posLimit,
+		IssuedAt:   now,
+	}
+
+	_, insertErr := database.Db.Model(&card).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &card, nil
+}
+
+func FindAllCardsByAccountNumber(accNumber uuid.UUID) ([]Card, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []Card
+	getErr := database.Db.Model(&cards).
+		Where("account_id = ?", account.ID).
+		Order("id").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	for i := range cards {
+		cards[i].Number = MaskCardNumber(cards[i].Number)
+	}
+
+	return cards, nil
+}
+
+func lockCard(tx *pg.Tx, number string) (*Card, error) {
+	if !validLuhn(number) {
+		return nil, errors.New("invalid card number")
+	}
+
+	var card Card
+	getErr := tx.Model(&card).
+		Where("number = ?", number).
+		For("UPDATE").
+		Select()
+
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("card does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &card, nil
+}
+
+// checkPIN compares pin with the card's PIN. A wrong PIN counts towards
+// MAX_PIN_ATTEMPTS, after which the card is blocked; the caller must commit
+// tx for the attempt to be remembered.
+func checkPIN(tx *pg.Tx, card *Card, pin string) error {
+	if bcrypt.CompareHashAndPassword([]byte(card.PINHash), []byte(pin)) != nil {
+		card.FailedPINAttempts++
+		if card.FailedPINAttempts >= MAX_PIN_ATTEMPTS {
+			card.Status = CARD_BLOCKED
+			card.BlockReason = CARD_BLOCK_PIN_LOCKOUT
+		}
+
+		_, updateErr := tx.Model(card).
+			Column("failed_pin_attempts", "status", "block_reason").
+			WherePK().
+			Update()
+		if updateErr != nil {
+			return updateErr
+		}
+
+		return ErrIncorrectPIN
+	}
+
+	if card.FailedPINAttempts > 0 {
+		card.FailedPINAttempts = 0
+		_, updateErr := tx.Model(card).
+			Column("failed_pin_attempts").
+			WherePK().
+			Update()
+		if updateErr != nil {
+			return updateErr
+		}
+	}
+
+	return nil
+}
+
+// verifyCard checks the card can be used and its PIN is right, counting a
+// wrong PIN as checkPIN does.
+func verifyCard(tx *pg.Tx, number string, pin string) (*Card, error) {
+	card, err := lockCard(tx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if card.Status != CARD_ACTIVE {
+		return nil, fmt.Errorf("card is %s", card.Status)
+	}
+
+	if time.Now().After(card.Expiry) {
+		return nil, errors.New("card has expired")
+	}
+
+	if err := checkPIN(tx, card, pin); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// usedToday totals today's debits and pending holds made with the card on
+// a channel.
+func (card *Card) usedToday(tx *pg.Tx, channel string) (float64, error) {
+	year, month, day := time.Now().Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+
+	var debited float64
+	err := tx.Model((*Transaction)(nil)).
+		ColumnExpr("coalesce(sum(amount), 0)").
+		Where("reference = ?", card.reference()).
+		Where("mode_of_payment = ?", channel).
+		Where("type_of_transaction = ?", TRANSACTION_WITHDRAW).
+		Where("time >= ?", midnight).
+		Select(pg.Scan(&debited))
+	if err != nil {
+		return 0, err
+	}
+
+	var held float64
+	err = tx.Model((*Hold)(nil)).
+		ColumnExpr("coalesce(sum(amount), 0)").
+		Where("source_reference = ?", card.reference()).
+		Where("reason = ?", channel).
+		Where("status = ?", HOLD_ACTIVE).
+		Where("placed_at >= ?", midnight).
+		Select(pg.Scan(&held))
+	if err != nil {
+		return 0, err
+	}
+
+	return debited + held, nil
+}
+
+// AuthorizeCard verifies a card-present request from a terminal. When
+// capture is true the account is debited straight away, otherwise the
+// amount is held for CARD_HOLD_VALIDITY until the merchant captures it.
+func AuthorizeCard(number string, pin string, amount float64, channel string, terminalID string, capture bool) (*CardAuthorization, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	authorization, err := authorizeCard(tx, number, pin, amount, channel, terminalID, capture)
+	if err == ErrIncorrectPIN {
+		tx.Commit()
+		return nil, err
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return authorization, nil
+}
+
+func authorizeCard(tx *pg.Tx, number string, pin string, amount float64, channel string, terminalID string, capture bool) (*CardAuthorization, error) {
+	card, err := verifyCard(tx, number, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := card.POSLimit
+	if channel == CHANNEL_ATM {
+		limit = card.ATMLimit
+		capture = true
+	}
+
+	used, err := card.usedToday(tx, channel)
+	if err != nil {
+		return nil, err
+	}
+	if used+amount > limit {
+		return nil, fmt.Errorf("daily %s limit exceeded", channel)
+	}
+
+	authorization := CardAuthorization{
+		CardNumber: MaskCardNumber(card.Number),
+		Channel:    channel,
+		TerminalID: terminalID,
+		Amount:     amount,
+	}
+
+	if capture {
+		if err := accountWithdrawal(tx, card.AccountID, amount, card.CustomerID); err != nil {
+			return nil, err
+		}
+
+		transaction := Transaction{
+			AccountID:         card.AccountID,
+			ModeOfPayment:     channel,
+			TypeOfTransaction: TRANSACTION_WITHDRAW,
+			Amount:            amount,
+			Reference:         card.reference(),
+		}
+		if err := recordTransaction(tx, &transaction); err != nil {
+			return nil, err
+		}
+
+		authorization.Transaction = &transaction
+		return &authorization, nil
+	}
+
+	account, err := lockAccount(tx, "id = ?", card.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.CanDebit(); err != nil {
+		return nil, err
+	}
+	if err := checkOperatingMode(tx, account, card.CustomerID, amount); err != nil {
+		return nil, err
+	}
+
+	var held float64
+	err = activeHoldsTotal(tx, account.ID).Select(pg.Scan(&held))
+	if err != nil {
+		return nil, err
+	}
+	if account.Balance-held-amount < MIN_BALANCE {
+		return nil, ErrInsufficientBalance
+	}
+
+	now := time.Now()
+	hold := Hold{
+		AccountID:       account.ID,
+		Amount:          amount,
+		Reason:          channel,
+		SourceReference: card.reference(),
+		Expiry:          now.Add(CARD_HOLD_VALIDITY),
+		Status:          HOLD_ACTIVE,
+		PlacedAt:        now,
+	}
+	_, insertErr := tx.Model(&hold).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	authorization.Hold = &hold
+	return &authorization, nil
+}
+
+// CaptureCardHold completes a held card authorization, releasing the hold
+// and debiting the account for the held amount.
+func CaptureCardHold(holdID uint) (*Transaction, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	var hold Hold
+	updateResult, updateErr := tx.Model(&hold).
+		Set("status = ?", HOLD_RELEASED).
+		Set("released_at = now()").
+		Where("id = ?", holdID).
+		Where("status = ?", HOLD_ACTIVE).
+		Where("source_reference LIKE 'CARD-%'").
+		Where("expiry > now()").
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+	if updateResult.RowsAffected() == 0 {
+		tx.Rollback()
+		return nil, errors.New("no active card authorization found")
+	}
+
+	var card Card
+	getErr := tx.Model(&card).
+		Where("? = 'CARD-' || id", hold.SourceReference).
+		Select()
+	if getErr != nil {
+		tx.Rollback()
+		return nil, getErr
+	}
+
+	if err := accountWithdrawal(tx, hold.AccountID, hold.Amount, card.CustomerID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	transaction := Transaction{
+		AccountID:         hold.AccountID,
+		ModeOfPayment:     hold.Reason,
+		TypeOfTransaction: TRANSACTION_WITHDRAW,
+		Amount:            hold.Amount,
+		Reference:         hold.SourceReference,
+	}
+	if err := recordTransaction(tx, &transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return &transaction, nil
+}
+
+// BlockCard blocks a card temporarily, or hot-lists it for good when it has
+// been lost or stolen. A card already blocked keeps the reason it was
+// first blocked for.
+func BlockCard(number string, hotlist bool) (*Card, error) {
+	status := CARD_BLOCKED
+	if hotlist {
+		status = CARD_HOTLISTED
+	}
+
+	var card Card
+	updateResult, updateErr := database.Db.Model(&card).
+		Set("status = ?", status).
+		Set("block_reason = COALESCE(block_reason, ?)", CARD_BLOCK_CUSTOMER).
+		Where("number = ?", number).
+		Where("status != ?", CARD_HOTLISTED).
+		Returning("*").
+		Update()
+
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	if updateResult.RowsAffected() == 0 {
+		return nil, errors.New("card does not exist or is hot-listed")
+	}
+
+	card.Number = MaskCardNumber(card.Number)
+	return &card, nil
+}
+
+// UnblockCard lets a customer lift a block they placed themselves, given the
+// card's PIN. Wrong PINs count towards MAX_PIN_ATTEMPTS as they do at a
+// terminal. Cards blocked after too many wrong PINs are unblocked by staff
+// through ReleaseCardBlock, and hot-listed cards stay blocked.
+func UnblockCard(number string, pin string) (*Card, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	card, err := lockCard(tx, number)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if card.Status != CARD_BLOCKED {
+		tx.Rollback()
+		return nil, errors.New("no blocked card found")
+	}
+	if card.BlockReason != CARD_BLOCK_CUSTOMER {
+		tx.Rollback()
+		return nil, errors.New("card was blocked after too many wrong PINs and must be unblocked by the branch")
+	}
+
+	if err := checkPIN(tx, card, pin); err != nil {
+		if err == ErrIncorrectPIN {
+			tx.Commit()
+		} else {
+			tx.Rollback()
+		}
+		return nil, err
+	}
+
+	if err := unblockCard(tx, card); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	card.Number = MaskCardNumber(card.Number)
+	return card, nil
+}
+
+// ReleaseCardBlock lets staff lift any block on a card, including a PIN
+// lockout, and resets its wrong PIN count. Hot-listed cards stay blocked.
+func ReleaseCardBlock(number string, actor string) (*Card, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	card, err := lockCard(tx, number)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if card.Status != CARD_BLOCKED {
+		tx.Rollback()
+		return nil, errors.New("no blocked card found")
+	}
+
+	blockReason, attempts := card.BlockReason, card.FailedPINAttempts
+	if err := unblockCard(tx, card); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	auditErr := RecordAudit(tx, "card", card.ID, "unblock", actor, map[string]interface{}{
+		"block_reason":        blockReason,
+		"failed_pin_attempts": attempts,
+	})
+	if auditErr != nil {
+		tx.Rollback()
+		return nil, auditErr
+	}
+
+	tx.Commit()
+	card.Number = MaskCardNumber(card.Number)
+	return card, nil
+}
+
+func unblockCard(tx *pg.Tx, card *Card) error {
+	card.Status = CARD_ACTIVE
+	card.BlockReason = ""
+	card.FailedPINAttempts = 0
+
+	_, updateErr := tx.Model(card).
+		Set("status = ?", card.Status).
+		Set("block_reason = NULL").
+		Set("failed_pin_attempts = 0").
+		WherePK().
+		Update()
+	return updateErr
+}
+
+func ChangeCardPIN(number string, oldPIN string, newPIN string) error {
+	hash, err := hashPIN(newPIN)
+	if err != nil {
+		return err
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	card, err := verifyCard(tx, number, oldPIN)
+	if err == ErrIncorrectPIN {
+		tx.Commit()
+		return err
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	card.PINHash = hash
+	_, updateErr := tx.Model(card).
+		Column("pin_hash").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return updateErr
+	}
+
+	tx.Commit()
+	return nil
+}
@@ -0,0 +1,410 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	CHEQUE_UNUSED  = "unused"
+	CHEQUE_PAID    = "paid"
+	CHEQUE_STOPPED = "stopped"
+	CHEQUE_BOUNCED = "bounced"
+)
+
+const (
+	FIRST_CHEQUE_NUMBER  = 100001
+	CHEQUE_STALE_MONTHS  = 3
+	CHEQUE_BOUNCE_CHARGE = 350.00
+)
+
+type ChequeBook struct {
+	ID        uint
+	AccountID uint     `pg:"on_delete:CASCADE"`
+	Account   *Account `pg:"rel:has-one"`
+	// CustomerID is the holder, or the guardian of a minor's account, the
+	// book was issued to. Cheques drawn on it are their debits.
+	CustomerID uint      `pg:"on_delete:RESTRICT"`
+	Customer   *Customer `pg:"rel:has-one"`
+	FirstLeaf  uint
+	LastLeaf   uint
+	IssuedAt   time.Time
+	Cheque     []*Cheque `pg:"rel:has-many"`
+}
+
+type Cheque struct {
+	ID            uint
+	ChequeBookID  uint `pg:"on_delete:CASCADE"`
+	AccountID     uint `pg:"on_delete:CASCADE"`
+	Number        uint `pg:",unique"`
+	Status        string
+	Amount        float64
+	Payee         string
+	ChequeDate    time.Time
+	PresentedAt   time.Time
+	StopReason    string
+	BounceReason  string
+	BounceCharge  float64
+	TransactionID uint
+}
+
+// IssueChequeBook issues a new cheque book of the given number of leaves.
+// Leaf numbers continue from the highest number issued so far.
+func IssueChequeBook(accountID uint, customerID uint, leaves uint) (*ChequeBook, error) {
+	if leaves == 0 {
+		return nil, errors.New("a cheque book needs at least one leaf")
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := account.CanDebit(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := checkOperator(tx, account.ID, customerID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Serialise issuance so two books never get overlapping leaves.
+	_, lockErr := tx.Exec("LOCK TABLE cheque_books IN EXCLUSIVE MODE")
+	if lockErr != nil {
+		tx.Rollback()
+		return nil, lockErr
+	}
+
+	var last uint
+	getErr := tx.Model((*ChequeBook)(nil)).
+		ColumnExpr("coalesce(max(last_leaf), ?)", FIRST_CHEQUE_NUMBER-1).
+		Select(pg.Scan(&last))
+	if getErr != nil {
+		tx.Rollback()
+		return nil, getErr
+	}
+
+	book := ChequeBook{
+		AccountID:  account.ID,
+		CustomerID: customerID,
+		FirstLeaf:  last + 1,
+		LastLeaf:   last + leaves,
+		IssuedAt:   time.Now(),
+	}
+
+	_, insertErr := tx.Model(&book).Returning("*").Insert()
+	if insertErr != nil {
+		tx.Rollback()
+		return nil, insertErr
+	}
+
+	for number := book.FirstLeaf; number <= book.LastLeaf; number++ {
+		book.Cheque = append(book.Cheque, &Cheque{
+			ChequeBookID: book.ID,
+			AccountID:    account.ID,
+			Number:       number,
+			Status:       CHEQUE_UNUSED,
+		})
+	}
+
+	_, insertErr = tx.Model(&book.Cheque).Returning("*").Insert()
+	if insertErr != nil {
+		tx.Rollback()
+		return nil, insertErr
+	}
+
+	tx.Commit()
+	return &book, nil
+}
+
+func FindAllChequeBooksByAccountID(id uint) ([]ChequeBook, error) {
+	var books []ChequeBook
+	getErr := database.Db.Model(&books).
+		Relation("Cheque").
+		Where("account_id = ?", id).
+		Order("id").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return books, nil
+}
+
+func FindAllChequesByAccountNumber(accNumber uuid.UUID) ([]Cheque, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var cheques []Cheque
+	getErr := database.Db.Model(&cheques).
+		Where("account_id = ?", account.ID).
+		Order("number").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return cheques, nil
+}
+
+func lockCheque(tx *pg.Tx, accountID uint, number uint) (*Cheque, error) {
+	var cheque Cheque
+	getErr := tx.Model(&cheque).
+		Where("account_id = ?", accountID).
+		Where("number = ?", number).
+		For("UPDATE").
+		Select()
+
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("cheque was not issued on this account")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &cheque, nil
+}
+
+// StopCheque places a stop-payment instruction on an unused cheque leaf.
+func StopCheque(accNumber uuid.UUID, number uint, reason string) (*Cheque, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	account, err := lockAccount(tx, "account_number = ?", accNumber)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	cheque, err := lockCheque(tx, account.ID, number)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if cheque.Status != CHEQUE_UNUSED {
+		tx.Rollback()
+		return nil, fmt.Errorf("cheque is already %s", cheque.Status)
+	}
+
+	cheque.Status = CHEQUE_STOPPED
+	cheque.StopReason = reason
+	_, updateErr := tx.Model(cheque).
+		Column("status", "stop_reason").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+
+	tx.Commit()
+	return cheque, nil
+}
+
+// ClearCheque presents a cheque drawn on an account. A cheque that passes
+// every check is paid to the payee account, or in cash if none is given.
+// Otherwise the leaf is recorded as bounced with the reason and, when the
+// drawer lacked funds, a bounce charge. The bounced cheque is returned with
+// a nil error since the bounce itself is recorded successfully.
+func ClearCheque(accNumber uuid.UUID, number uint, amount float64, payee string, chequeDate time.Time, payeeAccountNo uuid.UUID) (*Cheque, error) {
+	if amount <= 0 {
+		return nil, errors.New("cheque amount must be positive")
+	}
+	payee = strings.TrimSpace(payee)
+	if payee == "" {
+		return nil, errors.New("cheque payee is required")
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	cheque, err := clearCheque(tx, accNumber, number, amount, payee, chequeDate, payeeAccountNo)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return cheque, nil
+}
+
+func clearCheque(tx *pg.Tx, accNumber uuid.UUID, number uint, amount float64, payee string, chequeDate time.Time, payeeAccountNo uuid.UUID) (*Cheque, error) {
+	account, err := lockAccount(tx, "account_number = ?", accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	cheque, err := lockCheque(tx, account.ID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cheque.Status {
+	case CHEQUE_UNUSED, CHEQUE_STOPPED:
+	default:
+		return nil, fmt.Errorf("cheque has already been presented and is %s", cheque.Status)
+	}
+
+	now := time.Now()
+	cheque.Amount = amount
+	cheque.Payee = payee
+	cheque.ChequeDate = chequeDate
+	cheque.PresentedAt = now
+
+	if cheque.Status == CHEQUE_STOPPED {
+		return bounceCheque(tx, account, cheque, "payment stopped by drawer", 0)
+	}
+
+	// Cheques are dated by day, so the time of day plays no part.
+	today := dateOf(now)
+	if dateOf(chequeDate).Before(today.AddDate(0, -CHEQUE_STALE_MONTHS, 0)) {
+		return bounceCheque(tx, account, cheque, "stale cheque", 0)
+	}
+
+	if dateOf(chequeDate).After(today) {
+		return bounceCheque(tx, account, cheque, "post-dated cheque", 0)
+	}
+
+	if err := account.CanDebit(); err != nil {
+		return bounceCheque(tx, account, cheque, err.Error(), 0)
+	}
+
+	var drawer uint
+	getErr := tx.Model((*ChequeBook)(nil)).
+		Column("customer_id").
+		Where("id = ?", cheque.ChequeBookID).
+		Select(pg.Scan(&drawer))
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if err := checkOperatingMode(tx, account, drawer, amount); err != nil {
+		return bounceCheque(tx, account, cheque, err.Error(), 0)
+	}
+
+	var receiver *Account
+	if payeeAccountNo != uuid.Nil {
+		receiver, err = lockAccount(tx, "account_number = ?", payeeAccountNo)
+		if err != nil {
+			return nil, err
+		}
+		if err := receiver.CanCredit(); err != nil {
+			return nil, err
+		}
+	}
+
+	// A failed debit may already have swept funds in from a deposit; the
+	// savepoint undoes that before the cheque is bounced.
+	if _, err := tx.Exec("SAVEPOINT clear_cheque"); err != nil {
+		return nil, err
+	}
+
+	err = accountWithdrawal(tx, account.ID, amount, drawer)
+	if err == ErrInsufficientBalance {
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT clear_cheque"); err != nil {
+			return nil, err
+		}
+		return bounceCheque(tx, account, cheque, "insufficient funds", CHEQUE_BOUNCE_CHARGE)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := Transaction{
+		AccountID:         account.ID,
+		ModeOfPayment:     "Cheque",
+		TypeOfTransaction: TRANSACTION_WITHDRAW,
+		Amount:            amount,
+		Reference:         fmt.Sprint(cheque.Number),
+		Time:              now,
+	}
+
+	if receiver != nil {
+		if err := accountCredit(tx, receiver, amount); err != nil {
+			return nil, err
+		}
+		transaction.TypeOfTransaction = TRANSACTION_TRANSFER
+		transaction.ReceiverAccountNumber = receiver.AccountNumber
+	}
+
+	if err := recordTransaction(tx, &transaction); err != nil {
+		return nil, err
+	}
+
+	cheque.Status = CHEQUE_PAID
+	cheque.TransactionID = transaction.ID
+	_, updateErr := tx.Model(cheque).WherePK().Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	return cheque, nil
+}
+
+// bounceCheque records a returned cheque and debits the bounce charge, capped
+// at the available balance so that held funds and the minimum balance are
+// left alone.
+func bounceCheque(tx *pg.Tx, account *Account, cheque *Cheque, reason string, charge float64) (*Cheque, error) {
+	if charge > 0 {
+		var held float64
+		if err := activeHoldsTotal(tx, account.ID).Select(pg.Scan(&held)); err != nil {
+			return nil, err
+		}
+		charge = roundAmount(math.Min(charge, math.Max(account.Balance-held-MIN_BALANCE, 0)))
+	}
+
+	if charge > 0 {
+		_, updateErr := tx.Model(account).
+			Set("balance = balance - ?", charge).
+			WherePK().
+			Update()
+		if updateErr != nil {
+			return nil, updateErr
+		}
+
+		fee := Transaction{
+			AccountID:         account.ID,
+			ModeOfPayment:     "Internal",
+			TypeOfTransaction: TRANSACTION_FEE,
+			Amount:            charge,
+			Reference:         fmt.Sprint(cheque.Number),
+		}
+		if err := recordTransaction(tx, &fee); err != nil {
+			return nil, err
+		}
+	}
+
+	cheque.Status = CHEQUE_BOUNCED
+	cheque.BounceReason = reason
+	cheque.BounceCharge = charge
+	_, updateErr := tx.Model(cheque).WherePK().Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	return cheque, nil
+}
@@ -0,0 +1,252 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	SAVINGS_INTEREST_RATE = 0.035
+	EARLY_CLOSURE_FEE     = 500.00
+	EARLY_CLOSURE_PERIOD  = 365 * 24 * time.Hour
+)
+
+const (
+	PAYOUT_TRANSFER = "transfer"
+	PAYOUT_CASH     = "cash"
+)
+
+// AccountClosure describes the final settlement of a closed account.
+type AccountClosure struct {
+	Account         *Account
+	AccruedInterest float64
+	TaxWithheld     float64
+	ClosureFee      float64
+	NetPayout       float64
+	PayoutMode      string
+	Transactions    []*Transaction
+}
+
+// closureChecks are run before an account is closed; any error blocks the
+// closure. Sweep rules are the only standing instructions, and loans are
+// not modelled yet.
+var closureChecks = []func(tx *pg.Tx, account *Account) error{
+	checkNoActiveHolds,
+	checkNoSweepDeposit,
+	checkNoPendingInstructions,
+	checkNoPendingCollectRequests,
+}
+
+func checkNoActiveHolds(tx *pg.Tx, account *Account) error {
+	count, err := tx.Model((*Hold)(nil)).
+		Where("account_id = ?", account.ID).
+		Where("status = ?", HOLD_ACTIVE).
+		Where("(expiry IS NULL OR expiry > now())").
+		Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("account has active holds")
+	}
+	return nil
+}
+
+// accruedInterest is the simple interest earned on the current balance since
+// the last interest credit, or since the account was opened.
+func accruedInterest(tx *pg.Tx, account *Account, now time.Time) (float64, error) {
+	var last Transaction
+	err := tx.Model(&last).
+		Where("account_id = ?", account.ID).
+		Where("type_of_transaction = ?", TRANSACTION_INTEREST).
+		Order("time DESC").
+		Limit(1).
+		Select()
+
+	since := account.OpenedAt
+	if err == nil {
+		since = last.Time
+	} else if err != pg.ErrNoRows {
+		return 0, err
+	}
+
+	if since.IsZero() || account.Balance <= 0 {
+		return 0, nil
+	}
+
+	days := math.Floor(now.Sub(since).Hours() / 24)
+	return roundAmount(account.Balance * SAVINGS_INTEREST_RATE * days / 365), nil
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// CloseAccount settles and closes an account: it credits accrued interest,
+// withholds tax on it, charges the closure fee, pays the net balance out
+// either to another account or as cash, and finally marks the account
+// closed. Transactions are kept.
+func CloseAccount(accountID uint, payoutMode string, payoutAccountNo uuid.UUID, reason string, actor string) (*AccountClosure, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	closure, err := closeAccount(tx, accountID, payoutMode, payoutAccountNo, reason, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return closure, nil
+}
+
+func closeAccount(tx *pg.Tx, accountID uint, payoutMode string, payoutAccountNo uuid.UUID, reason string, actor string) (*AccountClosure, error) {
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Status == ACCOUNT_CLOSED {
+		return nil, errors.New("account is already closed")
+	}
+
+	if !canTransition(account.Status, ACCOUNT_CLOSED) {
+		return nil, errors.New("account cannot be closed while it is " + account.Status)
+	}
+
+	for _, check := range closureChecks {
+		if err := check(tx, account); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	closure := AccountClosure{PayoutMode: payoutMode}
+
+	closure.AccruedInterest, err = accruedInterest(tx, account, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var withholding *TDSLedgerEntry
+	if closure.AccruedInterest > 0 {
+		withholding, err = assessTax(tx, account, closure.AccruedInterest, account.Balance+closure.AccruedInterest, now)
+		if err != nil {
+			return nil, err
+		}
+		closure.TaxWithheld = withholding.TaxWithheld
+	}
+
+	if !account.OpenedAt.IsZero() && now.Sub(account.OpenedAt) < EARLY_CLOSURE_PERIOD {
+		closure.ClosureFee = math.Min(EARLY_CLOSURE_FEE, account.Balance+closure.AccruedInterest-closure.TaxWithheld)
+	}
+
+	closure.NetPayout = roundAmount(account.Balance + closure.AccruedInterest - closure.TaxWithheld - closure.ClosureFee)
+
+	var receiver *Account
+	switch payoutMode {
+	case PAYOUT_CASH:
+	case PAYOUT_TRANSFER:
+		receiver, err = lockAccount(tx, "account_number = ?", payoutAccountNo)
+		if err != nil {
+			return nil, err
+		}
+		if receiver.ID == account.ID {
+			return nil, errors.New("payout account must differ from the account being closed")
+		}
+	default:
+		return nil, errors.New("payout mode must be transfer or cash")
+	}
+
+	postings := []*Transaction{}
+	if closure.AccruedInterest > 0 {
+		postings = append(postings, &Transaction{
+			AccountID:         account.ID,
+			ModeOfPayment:     "Internal",
+			TypeOfTransaction: TRANSACTION_INTEREST,
+			Amount:            closure.AccruedInterest,
+			Time:              now,
+		})
+	}
+	if closure.ClosureFee > 0 {
+		postings = append(postings, &Transaction{
+			AccountID:         account.ID,
+			ModeOfPayment:     "Internal",
+			TypeOfTransaction: TRANSACTION_FEE,
+			Amount:            closure.ClosureFee,
+			Time:              now,
+		})
+	}
+	if closure.NetPayout > 0 {
+		payout := &Transaction{
+			AccountID:         account.ID,
+			ModeOfPayment:     "Cash",
+			TypeOfTransaction: TRANSACTION_WITHDRAW,
+			Amount:            closure.NetPayout,
+			Time:              now,
+		}
+		if receiver != nil {
+			payout.ModeOfPayment = "Internal"
+			payout.TypeOfTransaction = TRANSACTION_TRANSFER
+			payout.ReceiverAccountNumber = receiver.AccountNumber
+
+			if err := accountCredit(tx, receiver, closure.NetPayout); err != nil {
+				return nil, err
+			}
+		}
+		postings = append(postings, payout)
+	}
+
+	for _, posting := range postings {
+		if err := recordTransaction(tx, posting); err != nil {
+			return nil, err
+		}
+		closure.Transactions = append(closure.Transactions, posting)
+
+		if posting.TypeOfTransaction == TRANSACTION_INTEREST {
+			tds, err := recordWithholding(tx, withholding, posting)
+			if err != nil {
+				return nil, err
+			}
+			if tds != nil {
+				closure.Transactions = append(closure.Transactions, tds)
+			}
+		}
+	}
+
+	_, updateErr := tx.Model(account).
+		Set("balance = 0").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	account.Balance = 0
+
+	closure.Account, err = changeAccountStatus(tx, account.ID, ACCOUNT_CLOSED, reason, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	auditErr := RecordAudit(tx, "account", account.ID, "closure", actor, map[string]interface{}{
+		"accrued_interest": closure.AccruedInterest,
+		"tax_withheld":     closure.TaxWithheld,
+		"closure_fee":      closure.ClosureFee,
+		"net_payout":       closure.NetPayout,
+		"payout_mode":      payoutMode,
+		"payout_account":   payoutAccountNo,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return &closure, nil
+}
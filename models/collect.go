@@ -0,0 +1,298 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	COLLECT_PENDING  = "pending"
+	COLLECT_APPROVED = "approved"
+	COLLECT_DECLINED = "declined"
+	COLLECT_EXPIRED  = "expired"
+)
+
+const COLLECT_DEFAULT_VALIDITY = 7 * 24 * time.Hour
+
+// CollectRequest is a request raised by the payee asking the payer to pay.
+type CollectRequest struct {
+	ID             uint
+	PayeeAccountID uint     `pg:"on_delete:CASCADE"`
+	PayeeAccount   *Account `pg:"rel:has-one"`
+	PayerAccountID uint     `pg:"on_delete:CASCADE"`
+	PayerAccount   *Account `pg:"rel:has-one"`
+	PayerVPA       string
+	PayeeVPA       string
+	Amount         float64
+	Note           string
+	Expiry         time.Time
+	Status         string
+	TransactionID  uint
+	CreatedAt      time.Time
+	RespondedAt    time.Time
+}
+
+// RaiseCollectRequest asks the payer, given by account number or VPA, to pay
+// the payee. A zero expiry defaults to COLLECT_DEFAULT_VALIDITY from now.
+func RaiseCollectRequest(payeeAccountNo uuid.UUID, payerAccountNo uuid.UUID, payerVPA string, amount float64, note string, expiry time.Time) (*CollectRequest, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	now := time.Now()
+	if expiry.IsZero() {
+		expiry = now.Add(COLLECT_DEFAULT_VALIDITY)
+	}
+	if !expiry.After(now) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	payee, err := FindAccountByAccountNumber(payeeAccountNo)
+	if err != nil {
+		return nil, err
+	}
+	if err := payee.CanCredit(); err != nil {
+		return nil, err
+	}
+
+	var payer *Account
+	if payerVPA != "" {
+		payer, err = FindAccountByVPA(payerVPA)
+	} else {
+		payer, err = FindAccountByAccountNumber(payerAccountNo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if payer.ID == payee.ID {
+		return nil, errors.New("payer and payee accounts must differ")
+	}
+
+	payeeVPA, err := findVPAAddressByAccountID(payee.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := CollectRequest{
+		PayeeAccountID: payee.ID,
+		PayerAccountID: payer.ID,
+		PayerVPA:       payerVPA,
+		PayeeVPA:       payeeVPA,
+		Amount:         amount,
+		Note:           note,
+		Expiry:         expiry,
+		Status:         COLLECT_PENDING,
+		CreatedAt:      now,
+	}
+
+	_, insertErr := database.Db.Model(&request).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &request, nil
+}
+
+// FindAllCollectRequestsByAccountNumber lists the requests where the account
+// is the payer or the payee, optionally filtered by status.
+func FindAllCollectRequestsByAccountNumber(accNumber uuid.UUID, role string, status string) ([]CollectRequest, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []CollectRequest
+	query := database.Db.Model(&requests).
+		Relation("PayeeAccount").
+		Relation("PayerAccount").
+		Order("collect_request.id DESC")
+
+	switch role {
+	case "payer":
+		query = query.Where("collect_request.payer_account_id = ?", account.ID)
+	case "payee":
+		query = query.Where("collect_request.payee_account_id = ?", account.ID)
+	default:
+		return nil, errors.New("role must be payer or payee")
+	}
+
+	if status != "" {
+		query = query.Where("collect_request.status = ?", status)
+	}
+
+	getErr := query.Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return requests, nil
+}
+
+// lockPendingCollectRequest loads a pending request for update and checks the
+// caller is its payer.
+func lockPendingCollectRequest(tx *pg.Tx, id uint, payerAccountNo uuid.UUID) (*CollectRequest, *Account, error) {
+	var request CollectRequest
+	getErr := tx.Model(&request).
+		Where("id = ?", id).
+		For("UPDATE").
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, nil, errors.New("collect request does not exist")
+	}
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	payer, err := lockAccount(tx, "id = ?", request.PayerAccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if payer.AccountNumber != payerAccountNo {
+		return nil, nil, errors.New("only the payer can respond to a collect request")
+	}
+
+	if request.Status != COLLECT_PENDING {
+		return nil, nil, fmt.Errorf("collect request is already %s", request.Status)
+	}
+
+	if !request.Expiry.After(time.Now()) {
+		return nil, nil, errors.New("collect request has expired")
+	}
+
+	return &request, payer, nil
+}
+
+// ApproveCollectRequest pays a pending request from the payer's account as a
+// debit by the approving holder.
+func ApproveCollectRequest(id uint, payerAccountNo uuid.UUID, approvedBy uint) (*CollectRequest, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	request, err := approveCollectRequest(tx, id, payerAccountNo, approvedBy)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return request, nil
+}
+
+func approveCollectRequest(tx *pg.Tx, id uint, payerAccountNo uuid.UUID, approvedBy uint) (*CollectRequest, error) {
+	request, payer, err := lockPendingCollectRequest(tx, id, payerAccountNo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := accountWithdrawal(tx, payer.ID, request.Amount, approvedBy); err != nil {
+		return nil, err
+	}
+
+	payee, err := lockAccount(tx, "id = ?", request.PayeeAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := accountCredit(tx, payee, request.Amount); err != nil {
+		return nil, err
+	}
+
+	transaction := Transaction{
+		AccountID:             payer.ID,
+		ReceiverAccountNumber: payee.AccountNumber,
+		ReceiverVPA:           request.PayeeVPA,
+		ModeOfPayment:         "Collect",
+		TypeOfTransaction:     TRANSACTION_TRANSFER,
+		Amount:                request.Amount,
+		Reference:             fmt.Sprintf("COLLECT-%d", request.ID),
+	}
+	if err := recordTransaction(tx, &transaction); err != nil {
+		return nil, err
+	}
+
+	request.Status = COLLECT_APPROVED
+	request.TransactionID = transaction.ID
+	request.RespondedAt = transaction.Time
+	_, updateErr := tx.Model(request).
+		Column("status", "transaction_id", "responded_at").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	return request, nil
+}
+
+func DeclineCollectRequest(id uint, payerAccountNo uuid.UUID) (*CollectRequest, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	request, _, err := lockPendingCollectRequest(tx, id, payerAccountNo)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	request.Status = COLLECT_DECLINED
+	request.RespondedAt = time.Now()
+	_, updateErr := tx.Model(request).
+		Column("status", "responded_at").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+
+	tx.Commit()
+	return request, nil
+}
+
+// ExpireCollectRequests marks pending requests past their expiry as expired.
+func ExpireCollectRequests() error {
+	return expireCollectRequests(0)
+}
+
+// expireCollectRequests expires the requests of payers at the bank.
+func expireCollectRequests(bankID uint) error {
+	query := database.Db.Model((*CollectRequest)(nil)).
+		Set("status = ?", COLLECT_EXPIRED).
+		Where("status = ?", COLLECT_PENDING).
+		Where("expiry <= now()")
+
+	_, updateErr := inBank(query, "payer_account_id", bankID).Update()
+	return updateErr
+}
+
+// checkNoPendingCollectRequests blocks the closure of an account that is
+// the payer or payee of a collect request still open for approval.
+func checkNoPendingCollectRequests(tx *pg.Tx, account *Account) error {
+	count, err := tx.Model((*CollectRequest)(nil)).
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("payer_account_id = ?", account.ID).
+				WhereOr("payee_account_id = ?", account.ID), nil
+		}).
+		Where("status = ?", COLLECT_PENDING).
+		Where("expiry > now()").
+		Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("account has pending collect requests")
+	}
+	return nil
+}
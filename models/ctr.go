@@ -0,0 +1,330 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	CTR_RULE_SINGLE = "single"
+	CTR_RULE_DAILY  = "daily"
+)
+
+const (
+	CTR_PENDING    = "pending"
+	CTR_REPORTABLE = "reportable"
+	CTR_DISMISSED  = "dismissed"
+	CTR_FILED      = "filed"
+)
+
+const CTR_MONTH_LAYOUT = "2006-01"
+
+// CTR_DEFAULT_MODES are the modes of payment a rule covers when none are
+// given: cash over the counter and at ATMs.
+var CTR_DEFAULT_MODES = []string{"cash", strings.ToLower(CHANNEL_ATM)}
+
+// CTRRule flags cash transactions of a customer that reach Threshold,
+// either in a single transaction or added up per day and type across all
+// the accounts the customer holds. Modes are matched case-insensitively.
+type CTRRule struct {
+	ID        uint
+	Name      string
+	Kind      string
+	Threshold float64
+	Modes     []string `pg:",array"`
+	Active    bool     `pg:",use_zero"`
+	CreatedAt time.Time
+}
+
+// CTRFlag is one item found by a rule, awaiting review. Single-transaction
+// items carry the transaction; daily items have TransactionID 0 and list
+// every transaction that made up the total.
+type CTRFlag struct {
+	ID                uint
+	CTRRuleID         uint      `pg:"ctr_rule_id,on_delete:CASCADE,unique:ctr_item"`
+	CTRRule           *CTRRule  `pg:"rel:has-one"`
+	CustomerID        uint      `pg:"on_delete:CASCADE,unique:ctr_item"`
+	Customer          *Customer `pg:"rel:has-one"`
+	Day               time.Time `pg:"type:date,unique:ctr_item"`
+	TransactionID     uint      `pg:",use_zero,unique:ctr_item"`
+	TypeOfTransaction string    `pg:",unique:ctr_item"`
+	Amount            float64
+	TransactionIDs    []uint `pg:"transaction_ids,array"`
+	Status            string
+	ReviewedBy        string
+	ReviewNote        string
+	ReviewedAt        time.Time
+	FiledAt           time.Time
+	CreatedAt         time.Time
+}
+
+func SaveCTRRule(rule *CTRRule) (*CTRRule, error) {
+	if rule.Kind != CTR_RULE_SINGLE && rule.Kind != CTR_RULE_DAILY {
+		return nil, errors.New("rule kind must be single or daily")
+	}
+	if rule.Threshold <= 0 {
+		return nil, errors.New("threshold must be positive")
+	}
+
+	if len(rule.Modes) == 0 {
+		rule.Modes = append([]string{}, CTR_DEFAULT_MODES...)
+	}
+	for i, mode := range rule.Modes {
+		rule.Modes[i] = strings.ToLower(strings.TrimSpace(mode))
+	}
+
+	if rule.ID == 0 {
+		rule.CreatedAt = time.Now()
+		_, insertErr := database.Db.Model(rule).Returning("*").Insert()
+		if insertErr != nil {
+			return nil, insertErr
+		}
+		return rule, nil
+	}
+
+	result, updateErr := database.Db.Model(rule).
+		Column("name", "kind", "threshold", "modes", "active").
+		WherePK().
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("rule does not exist")
+	}
+
+	return rule, nil
+}
+
+func FindAllCTRRules() ([]CTRRule, error) {
+	var rules []CTRRule
+	getErr := database.Db.Model(&rules).Order("id").Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return rules, nil
+}
+
+// FlagCashTransactions applies the active rules to the transactions made on
+// the days from and to, both included. It can be run any number of times:
+// items already found are kept, and daily totals still pending review are
+// brought up to date.
+func FlagCashTransactions(from time.Time, to time.Time) error {
+	var rules []CTRRule
+	getErr := database.Db.Model(&rules).Where("active").Select()
+	if getErr != nil {
+		return getErr
+	}
+
+	start, end := dateOf(from), dateOf(to).AddDate(0, 0, 1)
+	for _, rule := range rules {
+		var err error
+		switch rule.Kind {
+		case CTR_RULE_SINGLE:
+			_, err = database.Db.Exec(`
+				INSERT INTO ctr_flags (ctr_rule_id, customer_id, day, transaction_id, type_of_transaction, amount, transaction_ids, status, created_at)
+				SELECT ?, m.customer_id, t.time::date, t.id, t.type_of_transaction, t.amount, ARRAY[t.id], ?, now()
+				FROM transactions t JOIN customer_to_accounts m ON m.account_id = t.account_id AND `+holderRoles+`
+				WHERE lower(t.mode_of_payment) IN (?) AND t.amount >= ?
+					AND t.time >= ?::date AND t.time < ?::date
+				ON CONFLICT (ctr_rule_id, customer_id, day, transaction_id, type_of_transaction) DO NOTHING`,
+				rule.ID, CTR_PENDING, pg.In(rule.Modes), rule.Threshold, start, end)
+		case CTR_RULE_DAILY:
+			_, err = database.Db.Exec(`
+				INSERT INTO ctr_flags (ctr_rule_id, customer_id, day, transaction_id, type_of_transaction, amount, transaction_ids, status, created_at)
+				SELECT ?, m.customer_id, t.time::date, 0, t.type_of_transaction, sum(t.amount), array_agg(t.id ORDER BY t.id), ?, now()
+				FROM transactions t JOIN customer_to_accounts m ON m.account_id = t.account_id AND `+holderRoles+`
+				WHERE lower(t.mode_of_payment) IN (?)
+					AND t.time >= ?::date AND t.time < ?::date
+				GROUP BY m.customer_id, t.time::date, t.type_of_transaction
+				HAVING sum(t.amount) >= ?
+				ON CONFLICT (ctr_rule_id, customer_id, day, transaction_id, type_of_transaction) DO UPDATE
+				SET amount = EXCLUDED.amount, transaction_ids = EXCLUDED.transaction_ids
+				WHERE ctr_flags.status = ?`,
+				rule.ID, CTR_PENDING, pg.In(rule.Modes), start, end, rule.Threshold, CTR_PENDING)
+		}
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", rule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// FlagRecentCashTransactions rescans yesterday and today, picking up
+// transactions booked since the last scan.
+func FlagRecentCashTransactions() error {
+	now := time.Now()
+	return FlagCashTransactions(now.AddDate(0, 0, -1), now)
+}
+
+// monthRange returns the first day of month and the first day after it.
+func monthRange(month string) (time.Time, time.Time, error) {
+	start, err := time.Parse(CTR_MONTH_LAYOUT, month)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("month must be in YYYY-MM format")
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+func FindAllCTRFlags(month string, status string) ([]CTRFlag, error) {
+	start, end, err := monthRange(month)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags []CTRFlag
+	query := database.Db.Model(&flags).
+		Relation("CTRRule").
+		Relation("Customer").
+		Where("ctr_flag.day >= ?", start).
+		Where("ctr_flag.day < ?", end)
+	if status != "" {
+		query = query.Where("ctr_flag.status = ?", status)
+	}
+
+	getErr := query.Order("ctr_flag.day", "ctr_flag.id").Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return flags, nil
+}
+
+// ReviewCTRFlag records the decision on a flagged item: reportable items go
+// into the monthly report, dismissed ones do not. Filed items are final.
+func ReviewCTRFlag(id uint, status string, note string, actor string) (*CTRFlag, error) {
+	if status != CTR_REPORTABLE && status != CTR_DISMISSED {
+		return nil, errors.New("review status must be reportable or dismissed")
+	}
+
+	flag := CTRFlag{
+		ID:         id,
+		Status:     status,
+		ReviewNote: note,
+		ReviewedBy: actor,
+		ReviewedAt: time.Now(),
+	}
+	result, updateErr := database.Db.Model(&flag).
+		Column("status", "review_note", "reviewed_by", "reviewed_at").
+		WherePK().
+		Where("status <> ?", CTR_FILED).
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("flagged item does not exist or has already been filed")
+	}
+
+	return &flag, nil
+}
+
+// GenerateCTRReport scans the month and writes the report of its reportable
+// and filed items, marking the reportable ones filed. Every item must be
+// reviewed first.
+//
+// The report is fixed-width text: a header record, one detail record per
+// item and a trailer record. Amounts are in paise, zero-padded.
+//
+//	H month(7) generated(10) records(8)
+//	D item(10) rule(6) day(10) customer(10) name(40) pan(10) type(12) amount(15) transactions(5)
+//	T records(8) amount(18)
+func GenerateCTRReport(month string) ([]byte, error) {
+	start, end, err := monthRange(month)
+	if err != nil {
+		return nil, err
+	}
+
+	err = FlagCashTransactions(start, end.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	pending, countErr := database.Db.Model((*CTRFlag)(nil)).
+		Where("day >= ?", start).
+		Where("day < ?", end).
+		Where("status = ?", CTR_PENDING).
+		Count()
+	if countErr != nil {
+		return nil, countErr
+	}
+	if pending > 0 {
+		return nil, fmt.Errorf("%d flagged items are awaiting review", pending)
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	report, err := generateCTRReport(tx, month, start, end)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return report, nil
+}
+
+func generateCTRReport(tx *pg.Tx, month string, start time.Time, end time.Time) ([]byte, error) {
+	now := time.Now()
+	_, updateErr := tx.Model((*CTRFlag)(nil)).
+		Set("status = ?", CTR_FILED).
+		Set("filed_at = ?", now).
+		Where("day >= ?", start).
+		Where("day < ?", end).
+		Where("status = ?", CTR_REPORTABLE).
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	var flags []CTRFlag
+	getErr := tx.Model(&flags).
+		Relation("CTRRule").
+		Relation("Customer").
+		Where("ctr_flag.day >= ?", start).
+		Where("ctr_flag.day < ?", end).
+		Where("ctr_flag.status = ?", CTR_FILED).
+		Order("ctr_flag.day", "ctr_flag.id").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "H%-7s%-10s%08d\n", month, now.Format("2006-01-02"), len(flags))
+
+	var total int64
+	for _, flag := range flags {
+		paise := int64(math.Round(flag.Amount * 100))
+		total += paise
+
+		kind, name, pan := "", "", ""
+		if flag.CTRRule != nil {
+			kind = strings.ToUpper(flag.CTRRule.Kind)
+		}
+		if flag.Customer != nil {
+			name, pan = flag.Customer.Name, strings.ToUpper(flag.Customer.PAN)
+		}
+
+		fmt.Fprintf(&out, "D%010d%-6.6s%-10s%010d%-40.40s%-10.10s%-12.12s%015d%05d\n",
+			flag.ID, kind, flag.Day.Format("2006-01-02"), flag.CustomerID, name, pan,
+			flag.TypeOfTransaction, paise, len(flag.TransactionIDs))
+	}
+
+	fmt.Fprintf(&out, "T%08d%018d\n", len(flags), total)
+	return out.Bytes(), nil
+}
@@ -3,7 +3,10 @@ package models
 import (
 	"github.com/shouryagautam/bankdeploy/database"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/google/uuid"
 )
@@ -12,21 +15,64 @@ type Customer struct{
 	ID uint
 	BranchID uint `pg:"on_delete:CASCADE"`
 	Branch *Branch `pg:"rel:has-one"`
+	// BankID is the bank of the branch, kept so that a PAN is unique per bank.
+	BankID uint `pg:",unique:bank_pan"`
 	Name string
-	PAN string
+	PAN string `pg:",unique:bank_pan"`
 	DOB string `pg:"type:date"`
 	Age uint
 	Phone uint
 	Address string
+	KYCVerifiedAt time.Time
+	DeceasedOn time.Time
+	// MergedInto points a duplicate record merged by MergeCustomers at the
+	// customer it was merged into.
+	MergedInto uint
+	MergedAt time.Time
 	Account []*Account `pg:"many2many:customer_to_accounts"`
 }
 
+// ErrDuplicatePAN is returned when a bank already has a customer with the PAN.
+var ErrDuplicatePAN = errors.New("a customer with this PAN already exists in the bank")
+
+// setBank normalises the PAN and records the bank of the customer's branch.
+func (customer *Customer) setBank(db orm.DB) error {
+	customer.PAN = NormalizePAN(customer.PAN)
+
+	_, err := db.QueryOne(pg.Scan(&customer.BankID), "SELECT bank_id FROM branches WHERE id = ?", customer.BranchID)
+	if err == pg.ErrNoRows {
+		return errors.New("branch does not exist")
+	}
+	return err
+}
+
+// CheckNotMerged fails for a duplicate record that has been merged into
+// another customer, whose record is to be used instead.
+func (customer *Customer) CheckNotMerged() error {
+	if customer.MergedInto != 0 {
+		return fmt.Errorf("customer %d has been merged into customer %d", customer.ID, customer.MergedInto)
+	}
+	return nil
+}
+
+// uniqueViolation maps a breach of the PAN constraint to ErrDuplicatePAN.
+func uniqueViolation(err error) error {
+	if pgErr, ok := err.(pg.Error); ok && pgErr.Field('C') == "23505" {
+		return ErrDuplicatePAN
+	}
+	return err
+}
+
 func (customer *Customer) Save() (*Customer, error) {
+	if err := customer.setBank(database.Db); err != nil {
+		return nil, err
+	}
+
 	_, insertErr := database.Db.Model(customer).Returning("*").Insert()
 
 	if insertErr != nil {
 	
-		return nil,insertErr
+		return nil,uniqueViolation(insertErr)
 	}
 
 	return customer, nil
@@ -61,23 +107,37 @@ func FindAllCustomers() ([]Customer,error) {
 	return customer,nil
 }
 
-func FindAllCustomersByBranchID(id uint) ([]Customer, error) {
-	var customer []Customer
-	getErr := database.Db.Model(&customer).
-		Where("branch_id = ?",id).
-		Select()
+var customerSortKeys = sortKeys{
+	"id":   "customer.id",
+	"name": "COALESCE(customer.name, '')",
+}
+
+func (customer *Customer) sortValue(key string) interface{} {
+	if key == "name" {
+		return customer.Name
+	}
+	return nil
+}
 
+func FindAllCustomersByBranchID(id uint, opts ListOptions) ([]Customer, *Page, error) {
+	var customers []Customer
+	query, err := opts.paginate(database.Db.Model(&customers).Where("customer.branch_id = ?", id), "customer", customerSortKeys)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	getErr := query.Select()
 	if getErr != nil {
-		return nil,getErr
+		return nil, nil, getErr
 	}
 
-	return customer,nil
+	page, count := opts.page(len(customers), func(i int) (interface{}, uint) {
+		return customers[i].sortValue(opts.Sort), customers[i].ID
+	})
 
+	return customers[:count], page, nil
 }
 
-
-
 func DeleteAllCustomers()  error {
 	var customer Customer
 
@@ -144,11 +204,36 @@ func (customer *Customer) Update() (*Customer, error)  {
 		return nil,txErr
 	}
 
-	updateResult, updateErr := tx.Model(customer).WherePK().Returning("*").Update(customer)
+	if err := customer.setBank(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var current Customer
+	getErr := tx.Model(&current).Where("id = ?", customer.ID).For("UPDATE").Select()
+	if getErr == pg.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("no record updated")
+	}
+	if getErr != nil {
+		tx.Rollback()
+		return nil, getErr
+	}
+	if err := current.CheckNotMerged(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// KYC verification, death and merges are only stamped by their workflows.
+	updateResult, updateErr := tx.Model(customer).
+		ExcludeColumn("kyc_verified_at", "deceased_on", "merged_into", "merged_at").
+		WherePK().
+		Returning("*").
+		Update(customer)
 
 	if updateErr != nil {
 		tx.Rollback()
-		return nil,updateErr
+		return nil,uniqueViolation(updateErr)
 	}
 
 	if updateResult.RowsAffected() == 0 {
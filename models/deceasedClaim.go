@@ -0,0 +1,536 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	CLAIM_OPEN              = "open"
+	CLAIM_PARTIALLY_SETTLED = "partially_settled"
+	CLAIM_SETTLED           = "settled"
+)
+
+// CLAIM_REQUIRED_DOCUMENTS must all be submitted before a claim is approved.
+var CLAIM_REQUIRED_DOCUMENTS = []string{"death_certificate", "claim_form"}
+
+// DeceasedClaim settles the accounts of a deceased customer. SoleAccountIDs
+// are paid out to their nominees; FrozenAccountIDs are the jointly operated
+// accounts frozen until the claim is settled. Unsettled lists the sole
+// accounts the last approval could not settle, which a later approval
+// retries.
+type DeceasedClaim struct {
+	ID               uint
+	CustomerID       uint      `pg:"on_delete:CASCADE"`
+	Customer         *Customer `pg:"rel:has-one"`
+	DateOfDeath      time.Time
+	Status           string
+	SoleAccountIDs   []uint `pg:",array"`
+	FrozenAccountIDs []uint `pg:",array"`
+	Unsettled        []UnsettledAccount
+	ReportedBy       string
+	ApprovedBy       string
+	CreatedAt        time.Time
+	SettledAt        time.Time
+	Documents        []*ClaimDocument `pg:"rel:has-many"`
+	Payouts          []*ClaimPayout   `pg:"rel:has-many"`
+}
+
+type ClaimDocument struct {
+	ID              uint
+	DeceasedClaimID uint `pg:"on_delete:CASCADE"`
+	DocumentType    string
+	Reference       string
+	SubmittedAt     time.Time
+}
+
+// UnsettledAccount is a sole account a claim could not settle, and why.
+type UnsettledAccount struct {
+	AccountID uint
+	Reason    string
+}
+
+type ClaimPayout struct {
+	ID                  uint
+	DeceasedClaimID     uint `pg:"on_delete:CASCADE"`
+	AccountID           uint
+	NomineeID           uint
+	NomineeName         string
+	SharePercentage     float64
+	Amount              float64
+	PayoutMode          string
+	PayoutAccountNumber uuid.UUID `pg:"type:uuid"`
+	TransactionID       uint
+}
+
+// ClaimPayoutInstruction says how the share of one nominee, given by the ID
+// of their nominee entry, is paid: transferred to an account or in cash.
+// Nominees without an instruction are paid in cash.
+type ClaimPayoutInstruction struct {
+	NomineeID           uint
+	PayoutMode          string
+	PayoutAccountNumber uuid.UUID
+}
+
+// heldAccounts splits the open accounts held by a customer into those they
+// hold alone and those held jointly.
+func heldAccounts(tx *pg.Tx, customerID uint) ([]Account, []Account, error) {
+	var accounts []Account
+	getErr := tx.Model(&accounts).
+		Where("status != ?", ACCOUNT_CLOSED).
+		Where("id IN (SELECT account_id FROM customer_to_accounts WHERE customer_id = ? AND "+holderRoles+")", customerID).
+		Order("id").
+		Select()
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	var sole, joint []Account
+	for _, account := range accounts {
+		count, err := tx.Model((*CustomerToAccount)(nil)).
+			Where("account_id = ?", account.ID).
+			Where(holderRoles).
+			Count()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if count > 1 {
+			joint = append(joint, account)
+		} else {
+			sole = append(sole, account)
+		}
+	}
+
+	return sole, joint, nil
+}
+
+// ReportDeath marks a customer deceased and opens a claim. Accounts they
+// held alone are frozen for debits until the claim is settled. Joint
+// accounts in either-or-survivor or former-or-survivor mode pass to the
+// surviving holders straight away; jointly operated ones are frozen.
+// Accounts that cannot be frozen for debits alone are frozen totally.
+func ReportDeath(customerID uint, dateOfDeath time.Time, actor string) (*DeceasedClaim, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	claim, err := reportDeath(tx, customerID, dateOfDeath, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return claim, nil
+}
+
+func reportDeath(tx *pg.Tx, customerID uint, dateOfDeath time.Time, actor string) (*DeceasedClaim, error) {
+	var customer Customer
+	getErr := tx.Model(&customer).
+		Where("id = ?", customerID).
+		For("UPDATE").
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("customer does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if !customer.DeceasedOn.IsZero() {
+		return nil, errors.New("customer is already reported deceased")
+	}
+	if dateOfDeath.IsZero() || dateOfDeath.After(time.Now()) {
+		return nil, errors.New("date of death must be in the past")
+	}
+
+	customer.DeceasedOn = dateOfDeath
+	_, updateErr := tx.Model(&customer).
+		Column("deceased_on").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	sole, joint, err := heldAccounts(tx, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := DeceasedClaim{
+		CustomerID:  customer.ID,
+		DateOfDeath: dateOfDeath,
+		Status:      CLAIM_OPEN,
+		ReportedBy:  actor,
+		CreatedAt:   time.Now(),
+	}
+
+	reason := deathReason(customer.ID)
+	for _, account := range sole {
+		claim.SoleAccountIDs = append(claim.SoleAccountIDs, account.ID)
+		if _, err := freezeDebits(tx, &account, reason, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, account := range joint {
+		if account.OperatingMode == MODE_JOINTLY {
+			frozen, err := freezeDebits(tx, &account, reason, actor)
+			if err != nil {
+				return nil, err
+			}
+			if frozen {
+				claim.FrozenAccountIDs = append(claim.FrozenAccountIDs, account.ID)
+			}
+			continue
+		}
+
+		auditErr := RecordAudit(tx, "account", account.ID, "survivorship", actor, map[string]interface{}{
+			"deceased_customer_id": customer.ID,
+			"operating_mode":       account.OperatingMode,
+		})
+		if auditErr != nil {
+			return nil, auditErr
+		}
+	}
+
+	_, insertErr := tx.Model(&claim).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &claim, nil
+}
+
+// deathReason is the status reason of the accounts frozen by the death of
+// a customer.
+func deathReason(customerID uint) string {
+	return fmt.Sprintf("holder %d deceased", customerID)
+}
+
+func FindDeceasedClaimByID(id uint) (*DeceasedClaim, error) {
+	var claim DeceasedClaim
+	getErr := database.Db.Model(&claim).
+		Relation("Customer").
+		Relation("Documents").
+		Relation("Payouts").
+		Where("deceased_claim.id = ?", id).
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &claim, nil
+}
+
+func AddClaimDocument(claimID uint, documentType string, reference string) (*ClaimDocument, error) {
+	claim, err := FindDeceasedClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	if claim.Status == CLAIM_SETTLED {
+		return nil, errors.New("claim is already settled")
+	}
+
+	document := ClaimDocument{
+		DeceasedClaimID: claim.ID,
+		DocumentType:    documentType,
+		Reference:       reference,
+		SubmittedAt:     time.Now(),
+	}
+
+	_, insertErr := database.Db.Model(&document).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &document, nil
+}
+
+// ApproveDeceasedClaim settles a claim once the required documents are in:
+// every account the deceased held alone is credited its accrued interest,
+// paid out to its nominees by share as the payouts instruct and closed, and
+// the jointly operated accounts frozen by the claim are released to the
+// surviving holders. An account that cannot be settled is left as it is
+// and reported on the claim, which stays partially settled until a later
+// approval settles it.
+func ApproveDeceasedClaim(claimID uint, payouts []ClaimPayoutInstruction, actor string) (*DeceasedClaim, error) {
+	instructions := map[uint]ClaimPayoutInstruction{}
+	for _, payout := range payouts {
+		switch payout.PayoutMode {
+		case PAYOUT_CASH:
+		case PAYOUT_TRANSFER:
+			if payout.PayoutAccountNumber == uuid.Nil {
+				return nil, fmt.Errorf("nominee %d is paid by transfer but has no payout account", payout.NomineeID)
+			}
+		default:
+			return nil, errors.New("payout mode must be transfer or cash")
+		}
+		instructions[payout.NomineeID] = payout
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	err := approveDeceasedClaim(tx, claimID, instructions, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return FindDeceasedClaimByID(claimID)
+}
+
+func approveDeceasedClaim(tx *pg.Tx, claimID uint, instructions map[uint]ClaimPayoutInstruction, actor string) error {
+	var claim DeceasedClaim
+	getErr := tx.Model(&claim).
+		Where("id = ?", claimID).
+		For("UPDATE").
+		Select()
+	if getErr == pg.ErrNoRows {
+		return errors.New("claim does not exist")
+	}
+	if getErr != nil {
+		return getErr
+	}
+
+	if claim.Status == CLAIM_SETTLED {
+		return errors.New("claim is already settled")
+	}
+
+	for _, documentType := range CLAIM_REQUIRED_DOCUMENTS {
+		count, err := tx.Model((*ClaimDocument)(nil)).
+			Where("deceased_claim_id = ?", claim.ID).
+			Where("document_type = ?", documentType).
+			Count()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("claim is missing a %s", documentType)
+		}
+	}
+
+	// Each account is settled under a savepoint so that one which cannot be
+	// settled is rolled back on its own and the rest still go through.
+	reason := fmt.Sprintf("deceased claim %d settled", claim.ID)
+	claim.Unsettled = nil
+	for _, accountID := range claim.SoleAccountIDs {
+		if _, err := tx.Exec("SAVEPOINT settle_account"); err != nil {
+			return err
+		}
+
+		err := settleDeceasedAccount(tx, &claim, accountID, instructions, reason, actor)
+		if err != nil {
+			if _, rollbackErr := tx.Exec("ROLLBACK TO SAVEPOINT settle_account"); rollbackErr != nil {
+				return rollbackErr
+			}
+			claim.Unsettled = append(claim.Unsettled, UnsettledAccount{AccountID: accountID, Reason: err.Error()})
+			continue
+		}
+
+		if _, err := tx.Exec("RELEASE SAVEPOINT settle_account"); err != nil {
+			return err
+		}
+	}
+
+	for _, accountID := range claim.FrozenAccountIDs {
+		account, err := lockAccount(tx, "id = ?", accountID)
+		if err != nil {
+			return err
+		}
+		if account.StatusReason != deathReason(claim.CustomerID) {
+			continue
+		}
+
+		// A total freeze was a credit freeze, or dormancy, before the death.
+		status := ACCOUNT_ACTIVE
+		if account.Status == ACCOUNT_TOTAL_FROZEN {
+			status = ACCOUNT_CREDIT_FROZEN
+		}
+		if _, err := changeAccountStatus(tx, account.ID, status, reason, actor); err != nil {
+			return err
+		}
+	}
+
+	claim.Status = CLAIM_SETTLED
+	if len(claim.Unsettled) > 0 {
+		claim.Status = CLAIM_PARTIALLY_SETTLED
+	}
+	claim.ApprovedBy = actor
+	claim.SettledAt = time.Now()
+	_, updateErr := tx.Model(&claim).
+		Column("status", "unsettled", "approved_by", "settled_at").
+		WherePK().
+		Update()
+
+	return updateErr
+}
+
+func settleDeceasedAccount(tx *pg.Tx, claim *DeceasedClaim, accountID uint, instructions map[uint]ClaimPayoutInstruction, reason string, actor string) error {
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		return err
+	}
+
+	if account.Status == ACCOUNT_CLOSED {
+		return nil
+	}
+
+	var nominees []CustomerToAccount
+	getErr := tx.Model(&nominees).
+		Where("account_id = ?", account.ID).
+		Where("role = ?", ROLE_NOMINEE).
+		Order("id").
+		Select()
+	if getErr != nil {
+		return getErr
+	}
+
+	if len(nominees) == 0 && account.Balance > 0 {
+		return fmt.Errorf("account %s has no nominees to pay out to", account.AccountNumber)
+	}
+
+	switch account.Status {
+	case ACCOUNT_ACTIVE, ACCOUNT_DEBIT_FROZEN, ACCOUNT_DORMANT:
+	case ACCOUNT_TOTAL_FROZEN:
+		// Only a total freeze placed by the death itself is lifted here.
+		if account.StatusReason != deathReason(claim.CustomerID) {
+			return fmt.Errorf("account %s is %s and cannot be settled", account.AccountNumber, account.Status)
+		}
+	default:
+		return fmt.Errorf("account %s is %s and cannot be settled", account.AccountNumber, account.Status)
+	}
+
+	// The account is released from the freeze only to be paid out and closed.
+	if account.Status != ACCOUNT_ACTIVE {
+		account, err = changeAccountStatus(tx, account.ID, ACCOUNT_ACTIVE, reason, actor)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, check := range closureChecks {
+		if err := check(tx, account); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	interest, err := accruedInterest(tx, account, now)
+	if err != nil {
+		return err
+	}
+	tax := 0.0
+	if interest > 0 {
+		withholding, err := assessTax(tx, account, interest, account.Balance+interest, now)
+		if err != nil {
+			return err
+		}
+
+		credit := Transaction{
+			AccountID:         account.ID,
+			ModeOfPayment:     "Internal",
+			TypeOfTransaction: TRANSACTION_INTEREST,
+			Amount:            interest,
+			Time:              now,
+		}
+		if err := recordTransaction(tx, &credit); err != nil {
+			return err
+		}
+
+		if _, err := recordWithholding(tx, withholding, &credit); err != nil {
+			return err
+		}
+		tax = withholding.TaxWithheld
+	}
+
+	balance := roundAmount(account.Balance + interest - tax)
+	remaining := balance
+	for i, nominee := range nominees {
+		amount := roundAmount(balance * nominee.SharePercentage / 100)
+		if i == len(nominees)-1 {
+			amount = remaining
+		}
+		remaining = roundAmount(remaining - amount)
+
+		instruction, ok := instructions[nominee.ID]
+		if !ok {
+			instruction = ClaimPayoutInstruction{NomineeID: nominee.ID, PayoutMode: PAYOUT_CASH}
+		}
+
+		payout := ClaimPayout{
+			DeceasedClaimID:     claim.ID,
+			AccountID:           account.ID,
+			NomineeID:           nominee.ID,
+			NomineeName:         nominee.Name,
+			SharePercentage:     nominee.SharePercentage,
+			Amount:              amount,
+			PayoutMode:          instruction.PayoutMode,
+			PayoutAccountNumber: instruction.PayoutAccountNumber,
+		}
+
+		if amount > 0 {
+			transaction := Transaction{
+				AccountID:         account.ID,
+				ModeOfPayment:     "Cash",
+				TypeOfTransaction: TRANSACTION_WITHDRAW,
+				Amount:            amount,
+				Reference:         fmt.Sprintf("CLAIM-%d-NOMINEE-%d", claim.ID, nominee.ID),
+				Time:              now,
+			}
+
+			if instruction.PayoutMode == PAYOUT_TRANSFER {
+				receiver, err := lockAccount(tx, "account_number = ?", instruction.PayoutAccountNumber)
+				if err != nil {
+					return fmt.Errorf("payout account of nominee %d: %w", nominee.ID, err)
+				}
+				if receiver.ID == account.ID {
+					return errors.New("payout account must differ from the account being settled")
+				}
+				if err := accountCredit(tx, receiver, amount); err != nil {
+					return fmt.Errorf("payout account of nominee %d: %w", nominee.ID, err)
+				}
+
+				transaction.ModeOfPayment = "Internal"
+				transaction.TypeOfTransaction = TRANSACTION_TRANSFER
+				transaction.ReceiverAccountNumber = receiver.AccountNumber
+			}
+
+			if err := recordTransaction(tx, &transaction); err != nil {
+				return err
+			}
+			payout.TransactionID = transaction.ID
+		}
+
+		_, insertErr := tx.Model(&payout).Insert()
+		if insertErr != nil {
+			return insertErr
+		}
+	}
+
+	_, updateErr := tx.Model(account).
+		Set("balance = 0").
+		WherePK().
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return updateErr
+	}
+
+	_, err = changeAccountStatus(tx, account.ID, ACCOUNT_CLOSED, reason, actor)
+	return err
+}
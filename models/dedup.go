@@ -0,0 +1,183 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+// DUPLICATE_THRESHOLD is the score from which an existing customer is
+// returned as a possible duplicate of a new one.
+const DUPLICATE_THRESHOLD = 0.6
+
+const (
+	// Phone numbers also match on their last eight digits, which ignores
+	// country codes and trunk prefixes.
+	phoneMatchModulus = 100000000
+	// nameMatchSimilarity is the similarity from which names are reported
+	// as matching.
+	nameMatchSimilarity = 0.8
+)
+
+// DuplicateCandidate is an existing customer who may be the same person as
+// a customer being onboarded. The score weighs the name at one half and
+// the date of birth and phone number at one quarter each.
+type DuplicateCandidate struct {
+	Customer  *Customer
+	Score     float64
+	MatchedOn []string
+}
+
+// normalizeName lowercases a name, drops everything but letters and sorts
+// its words, so that case, punctuation and word order do not matter.
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// nameSimilarity is 1 for names that normalise to the same text, falling to
+// 0 with their edit distance.
+func nameSimilarity(a string, b string) float64 {
+	x, y := []rune(normalizeName(a)), []rune(normalizeName(b))
+	longest := max(len(x), len(y))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(x, y))/float64(longest)
+}
+
+// dobVariants parses a date of birth and also returns it with day and month
+// swapped, the commonest mistake when it is keyed in.
+func dobVariants(dob string) (time.Time, time.Time, bool) {
+	if len(dob) < 10 {
+		return time.Time{}, time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", dob[:10])
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	swapped := date
+	if date.Day() <= 12 {
+		swapped = time.Date(date.Year(), time.Month(date.Day()), int(date.Month()), 0, 0, 0, 0, time.UTC)
+	}
+	return date, swapped, true
+}
+
+// FindDuplicateCustomers returns the living customers of the bank of the
+// customer's branch who may be the same person, best match first. Only
+// customers sharing a date of birth or phone number, at least loosely, are
+// scored: a similar name alone does not reach the threshold.
+func FindDuplicateCustomers(customer *Customer) ([]DuplicateCandidate, error) {
+	probe := *customer
+	if err := probe.setBank(database.Db); err != nil {
+		return nil, err
+	}
+
+	dob, swapped, hasDOB := dobVariants(probe.DOB)
+	phone := probe.Phone % phoneMatchModulus
+
+	var existing []Customer
+	query := database.Db.Model(&existing).
+		Where("customer.bank_id = ?", probe.BankID).
+		Where("customer.deceased_on IS NULL").
+		Where("customer.merged_into IS NULL").
+		Where("customer.id <> ?", probe.ID)
+	if hasDOB {
+		query = query.WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("customer.dob IN (?::date, ?::date)", dob, swapped).
+				WhereOr("customer.phone % ? = ?", phoneMatchModulus, phone), nil
+		})
+	} else {
+		query = query.Where("customer.phone % ? = ?", phoneMatchModulus, phone)
+	}
+
+	getErr := query.Order("customer.id").Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	var candidates []DuplicateCandidate
+	for i := range existing {
+		other := &existing[i]
+		candidate := DuplicateCandidate{Customer: other}
+
+		similarity := nameSimilarity(probe.Name, other.Name)
+		candidate.Score += similarity / 2
+		if similarity >= nameMatchSimilarity {
+			candidate.MatchedOn = append(candidate.MatchedOn, "name")
+		}
+
+		if otherDOB, _, ok := dobVariants(other.DOB); ok && hasDOB {
+			if otherDOB.Equal(dob) {
+				candidate.Score += 0.25
+				candidate.MatchedOn = append(candidate.MatchedOn, "dob")
+			} else if otherDOB.Equal(swapped) {
+				candidate.Score += 0.125
+				candidate.MatchedOn = append(candidate.MatchedOn, "dob with day and month swapped")
+			}
+		}
+
+		if other.Phone == probe.Phone {
+			candidate.Score += 0.25
+			candidate.MatchedOn = append(candidate.MatchedOn, "phone")
+		} else if other.Phone%phoneMatchModulus == phone {
+			candidate.Score += 0.125
+			candidate.MatchedOn = append(candidate.MatchedOn, "phone ending")
+		}
+
+		candidate.Score = roundAmount(candidate.Score)
+		if candidate.Score >= DUPLICATE_THRESHOLD {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates, nil
+}
+
+// RecordDuplicateOverride audits a customer created by staff in spite of
+// possible duplicates, with their justification.
+func RecordDuplicateOverride(customerID uint, candidates []DuplicateCandidate, actor string, justification string) error {
+	candidateIDs := make([]uint, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidateIDs = append(candidateIDs, candidate.Customer.ID)
+	}
+
+	return RecordAudit(database.Db, "customer", customerID, "duplicate_override", actor, map[string]interface{}{
+		"candidates":    candidateIDs,
+		"justification": justification,
+	})
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	DORMANCY_MONTHS = 24
+	UNCLAIMED_YEARS = 10
+)
+
+// DORMANCY_KYC_PENDING is the block reason of dormant accounts, which are
+// reactivated only after the KYC of their holders is re-verified.
+const DORMANCY_KYC_PENDING = "dormant, KYC re-verification pending"
+
+const (
+	UNCLAIMED_OPEN    = "unclaimed"
+	UNCLAIMED_CLAIMED = "claimed"
+)
+
+// customerInitiatedTypes are the transaction types that count as activity
+// by the account holder; system postings such as interest do not.
+var customerInitiatedTypes = []string{
+	TRANSACTION_DEPOSIT,
+	TRANSACTION_WITHDRAW,
+	TRANSACTION_TRANSFER,
+}
+
+type UnclaimedDeposit struct {
+	ID           uint
+	AccountID    uint     `pg:"on_delete:CASCADE"`
+	Account      *Account `pg:"rel:has-one"`
+	BranchID     uint     `pg:"on_delete:CASCADE"`
+	Balance      float64
+	DormantSince time.Time
+	FlaggedAt    time.Time
+	Status       string
+	ClaimedAt    time.Time
+}
+
+// MarkDormantAccounts moves active accounts without any customer-initiated
+// transaction in the last DORMANCY_MONTHS months to dormant. Sweep deposits
+// only ever see sweep postings and are left alone. Accounts
+// without an opening date are judged by their transactions alone.
+func MarkDormantAccounts() error {
+	return markDormantAccounts(0)
+}
+
+func markDormantAccounts(bankID uint) error {
+	cutoff := time.Now().AddDate(0, -DORMANCY_MONTHS, 0)
+
+	var accounts []Account
+	query := database.Db.Model(&accounts).
+		Where("status = ?", ACCOUNT_ACTIVE).
+		Where("(opened_at IS NULL OR opened_at < ?)", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM sweep_rules s WHERE s.deposit_account_id = account.id)").
+		Where("NOT EXISTS (SELECT 1 FROM transactions t WHERE t.account_id = account.id AND t.type_of_transaction IN (?) AND t.time >= ?)", pg.In(customerInitiatedTypes), cutoff)
+
+	getErr := inBank(query, "account.id", bankID).Select()
+
+	if getErr != nil {
+		return getErr
+	}
+
+	reason := fmt.Sprintf("no customer-initiated transaction in %d months", DORMANCY_MONTHS)
+	for _, account := range accounts {
+		if err := markDormant(account.ID, reason); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func markDormant(accountID uint, reason string) error {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	_, err := changeAccountStatus(tx, accountID, ACCOUNT_DORMANT, reason, "system")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// FlagUnclaimedDeposits adds accounts dormant for UNCLAIMED_YEARS years to the
+// unclaimed-deposits register.
+func FlagUnclaimedDeposits() error {
+	return flagUnclaimedDeposits(0)
+}
+
+func flagUnclaimedDeposits(bankID uint) error {
+	cutoff := time.Now().AddDate(-UNCLAIMED_YEARS, 0, 0)
+
+	var accounts []Account
+	query := database.Db.Model(&accounts).
+		Where("status = ?", ACCOUNT_DORMANT).
+		Where("dormant_since < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM unclaimed_deposits u WHERE u.account_id = account.id AND u.status = ?)", UNCLAIMED_OPEN)
+
+	getErr := inBank(query, "account.id", bankID).Select()
+
+	if getErr != nil {
+		return getErr
+	}
+
+	for _, account := range accounts {
+		deposit := UnclaimedDeposit{
+			AccountID:    account.ID,
+			BranchID:     account.BranchID,
+			Balance:      account.Balance,
+			DormantSince: account.DormantSince,
+			FlaggedAt:    time.Now(),
+			Status:       UNCLAIMED_OPEN,
+		}
+
+		tx, txErr := database.Db.Begin()
+		if txErr != nil {
+			return txErr
+		}
+
+		_, insertErr := tx.Model(&deposit).Insert()
+		if insertErr != nil {
+			tx.Rollback()
+			return insertErr
+		}
+
+		auditErr := RecordAudit(tx, "account", account.ID, "unclaimed", "system", map[string]interface{}{
+			"balance":       account.Balance,
+			"dormant_since": account.DormantSince,
+		})
+		if auditErr != nil {
+			tx.Rollback()
+			return auditErr
+		}
+
+		tx.Commit()
+	}
+
+	return nil
+}
+
+// ReactivateAccount returns a dormant account to active once every holder
+// has a verified KYC record. Any open unclaimed-deposit entry is marked
+// claimed.
+func ReactivateAccount(accountID uint, actor string) (*Account, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if account.BlockReason != DORMANCY_KYC_PENDING {
+		tx.Rollback()
+		return nil, errors.New("account is not dormant")
+	}
+
+	if err := checkHoldersVerified(tx, account.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	_, updateErr := tx.Model((*UnclaimedDeposit)(nil)).
+		Set("status = ?", UNCLAIMED_CLAIMED).
+		Set("claimed_at = ?", now).
+		Where("account_id = ?", account.ID).
+		Where("status = ?", UNCLAIMED_OPEN).
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+
+	account, err = releaseBlock(tx, account, "reactivated after KYC re-verification", actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	_, updateErr = tx.Model(account).
+		Set("dormant_since = NULL").
+		WherePK().
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+
+	tx.Commit()
+	return account, nil
+}
+
+// DormancyReport lists a branch's dormant accounts and its unclaimed-deposits register.
+type DormancyReport struct {
+	BranchID  uint
+	Dormant   []Account
+	Unclaimed []UnclaimedDeposit
+}
+
+func FindDormancyReportByBranchID(id uint) (*DormancyReport, error) {
+	report := DormancyReport{BranchID: id}
+
+	getErr := database.Db.Model(&report.Dormant).
+		Where("branch_id = ?", id).
+		Where("status = ?", ACCOUNT_DORMANT).
+		Order("dormant_since").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	getErr = database.Db.Model(&report.Unclaimed).
+		Relation("Account").
+		Where("unclaimed_deposit.branch_id = ?", id).
+		Where("unclaimed_deposit.status = ?", UNCLAIMED_OPEN).
+		Order("unclaimed_deposit.flagged_at").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &report, nil
+}
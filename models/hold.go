@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	HOLD_ACTIVE   = "active"
+	HOLD_RELEASED = "released"
+	HOLD_EXPIRED  = "expired"
+)
+
+type Hold struct {
+	ID              uint
+	AccountID       uint     `pg:"on_delete:CASCADE"`
+	Account         *Account `pg:"rel:has-one"`
+	Amount          float64
+	Reason          string
+	SourceReference string
+	Expiry          time.Time
+	Status          string
+	PlacedAt        time.Time
+	ReleasedAt      time.Time
+}
+
+func (hold *Hold) Save() (*Hold, error) {
+	if hold.Amount <= 0 {
+		return nil, errors.New("hold amount must be positive")
+	}
+
+	if !hold.Expiry.IsZero() && hold.Expiry.Before(time.Now()) {
+		return nil, errors.New("hold expiry must be in the future")
+	}
+
+	account, err := FindAccountByID(hold.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status == ACCOUNT_CLOSED {
+		return nil, errors.New("account is closed")
+	}
+
+	hold.Status = HOLD_ACTIVE
+	hold.PlacedAt = time.Now()
+
+	_, insertErr := database.Db.Model(hold).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return hold, nil
+}
+
+// activeHoldsTotal builds a subquery summing the unexpired active holds on
+// an account, so it can be embedded in balance checks.
+func activeHoldsTotal(db orm.DB, accountID uint) *orm.Query {
+	return db.Model((*Hold)(nil)).
+		ColumnExpr("coalesce(sum(amount), 0)").
+		Where("account_id = ?", accountID).
+		Where("status = ?", HOLD_ACTIVE).
+		Where("(expiry IS NULL OR expiry > now())")
+}
+
+// AvailableBalance is the balance that can be debited from the account,
+// i.e. the balance less active holds and the minimum balance.
+func (account *Account) AvailableBalance() (float64, error) {
+	var held float64
+	err := activeHoldsTotal(database.Db, account.ID).Select(pg.Scan(&held))
+	if err != nil {
+		return 0, err
+	}
+
+	return account.Balance - held - MIN_BALANCE, nil
+}
+
+func FindHoldByID(id uint) (*Hold, error) {
+	var hold Hold
+	getErr := database.Db.Model(&hold).
+		Where("id = ?", id).
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &hold, nil
+}
+
+func FindAllHoldsByAccountID(id uint) ([]Hold, error) {
+	var holds []Hold
+	getErr := database.Db.Model(&holds).
+		Where("account_id = ?", id).
+		Order("id").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return holds, nil
+}
+
+func ReleaseHoldByID(id uint) (*Hold, error) {
+	var hold Hold
+	updateResult, updateErr := database.Db.Model(&hold).
+		Set("status = ?", HOLD_RELEASED).
+		Set("released_at = ?", time.Now()).
+		Where("id = ?", id).
+		Where("status = ?", HOLD_ACTIVE).
+		Returning("*").
+		Update()
+
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	if updateResult.RowsAffected() == 0 {
+		return nil, errors.New("no active hold found")
+	}
+
+	return &hold, nil
+}
+
+// ReleaseExpiredHolds marks every active hold whose expiry has passed as
+// expired.
+func ReleaseExpiredHolds() error {
+	_, updateErr := database.Db.Model((*Hold)(nil)).
+		Set("status = ?", HOLD_EXPIRED).
+		Set("released_at = now()").
+		Where("status = ?", HOLD_ACTIVE).
+		Where("expiry IS NOT NULL").
+		Where("expiry <= now()").
+		Update()
+
+	return updateErr
+}
@@ -0,0 +1,523 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/blob"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	KYC_PENDING  = "pending"
+	KYC_VERIFIED = "verified"
+	KYC_REJECTED = "rejected"
+	KYC_EXPIRED  = "expired"
+)
+
+const (
+	// KYC_VALIDITY_YEARS is how long a verification lasts before re-KYC is
+	// due, and KYC_REMINDER_DAYS how far ahead the branch is told.
+	KYC_VALIDITY_YEARS = 2
+	KYC_REMINDER_DAYS  = 30
+)
+
+// KYC_NOT_VERIFIED is the status reason of accounts frozen for debits
+// because a holder has no verified KYC.
+const KYC_NOT_VERIFIED = "holder KYC not verified"
+
+var kycDocumentTypes = map[string]bool{
+	"passport":        true,
+	"aadhaar":         true,
+	"voter_id":        true,
+	"driving_licence": true,
+	"pan":             true,
+}
+
+// KYCRecord is one identity document submitted for a customer. The scan is
+// kept in the blob store under FileKey. A verified record is valid until
+// ExpiresOn, when re-KYC falls due.
+type KYCRecord struct {
+	ID             uint
+	CustomerID     uint      `pg:"on_delete:CASCADE"`
+	Customer       *Customer `pg:"rel:has-one"`
+	DocumentType   string
+	DocumentNumber string
+	FileKey        string
+	FileName       string
+	ContentType    string
+	Status         string
+	SubmittedBy    string
+	SubmittedAt    time.Time
+	ReviewedBy     string
+	ReviewNote     string
+	ReviewedAt     time.Time
+	ExpiresOn      time.Time `pg:"type:date"`
+	RemindedAt     time.Time
+}
+
+// kycVerified reports whether the customer has a verified KYC record.
+func kycVerified(db orm.DB, customerID uint) (bool, error) {
+	return db.Model((*KYCRecord)(nil)).
+		Where("customer_id = ?", customerID).
+		Where("status = ?", KYC_VERIFIED).
+		Exists()
+}
+
+// checkHoldersVerified fails unless every holder of the account has a
+// verified KYC record.
+func checkHoldersVerified(db orm.DB, accountID uint) error {
+	holders, err := accountHolderIDs(db, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, holderID := range holders {
+		verified, err := kycVerified(db, holderID)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			return fmt.Errorf("customer %d has no verified KYC", holderID)
+		}
+	}
+
+	return nil
+}
+
+// OpeningStatus is the status and status reason of a new account held by
+// the given customers: frozen for debits unless every one of them has
+// verified KYC, so that deposits can be taken while it is completed.
+func OpeningStatus(customerIDs ...uint) (string, string, error) {
+	for _, customerID := range customerIDs {
+		verified, err := kycVerified(database.Db, customerID)
+		if err != nil {
+			return "", "", err
+		}
+		if !verified {
+			return ACCOUNT_DEBIT_FROZEN, KYC_NOT_VERIFIED, nil
+		}
+	}
+
+	return ACCOUNT_ACTIVE, "", nil
+}
+
+// SubmitKYC stores the scan of a customer's identity document and records
+// it for review.
+func SubmitKYC(customerID uint, documentType string, documentNumber string, fileName string, contentType string, file io.Reader, actor string) (*KYCRecord, error) {
+	if !kycDocumentTypes[documentType] {
+		return nil, errors.New("document type must be passport, aadhaar, voter_id, driving_licence or pan")
+	}
+
+	customer, err := FindCustomerByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := customer.CheckNotMerged(); err != nil {
+		return nil, err
+	}
+	if !customer.DeceasedOn.IsZero() {
+		return nil, errors.New("customer is deceased")
+	}
+
+	record := KYCRecord{
+		CustomerID:     customerID,
+		DocumentType:   documentType,
+		DocumentNumber: documentNumber,
+		FileKey:        fmt.Sprintf("kyc/%d/%s", customerID, uuid.New()),
+		FileName:       fileName,
+		ContentType:    contentType,
+		Status:         KYC_PENDING,
+		SubmittedBy:    actor,
+		SubmittedAt:    time.Now(),
+	}
+
+	if err := blob.Default.Put(record.FileKey, file); err != nil {
+		return nil, err
+	}
+
+	_, insertErr := database.Db.Model(&record).Returning("*").Insert()
+	if insertErr != nil {
+		blob.Default.Delete(record.FileKey)
+		return nil, insertErr
+	}
+
+	return &record, nil
+}
+
+func FindKYCRecordByID(id uint) (*KYCRecord, error) {
+	var record KYCRecord
+	getErr := database.Db.Model(&record).Where("id = ?", id).Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("KYC record does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &record, nil
+}
+
+func FindAllKYCRecordsByCustomerID(id uint) ([]KYCRecord, error) {
+	var records []KYCRecord
+	getErr := database.Db.Model(&records).
+		Where("customer_id = ?", id).
+		Order("id DESC").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return records, nil
+}
+
+// OpenKYCFile returns the record and its stored scan, which the caller
+// closes.
+func OpenKYCFile(id uint) (*KYCRecord, io.ReadCloser, error) {
+	record, err := FindKYCRecordByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := blob.Default.Get(record.FileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, file, nil
+}
+
+// ReviewKYC verifies or rejects a pending KYC record. Verifying it replaces
+// the customer's earlier verification, sets the re-KYC due date and lifts
+// the KYC and majority freezes on accounts whose holders are now all
+// verified.
+func ReviewKYC(id uint, status string, note string, actor string) (*KYCRecord, error) {
+	if status != KYC_VERIFIED && status != KYC_REJECTED {
+		return nil, errors.New("review status must be verified or rejected")
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	record, err := reviewKYC(tx, id, status, note, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return record, nil
+}
+
+func reviewKYC(tx *pg.Tx, id uint, status string, note string, actor string) (*KYCRecord, error) {
+	var record KYCRecord
+	getErr := tx.Model(&record).Where("id = ?", id).For("UPDATE").Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("KYC record does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if record.Status != KYC_PENDING {
+		return nil, fmt.Errorf("KYC record is already %s", record.Status)
+	}
+
+	now := time.Now()
+	record.Status = status
+	record.ReviewNote = note
+	record.ReviewedBy = actor
+	record.ReviewedAt = now
+
+	if status == KYC_VERIFIED {
+		record.ExpiresOn = dateOf(now).AddDate(KYC_VALIDITY_YEARS, 0, 0)
+
+		_, updateErr := tx.Model((*KYCRecord)(nil)).
+			Set("status = ?", KYC_EXPIRED).
+			Where("customer_id = ?", record.CustomerID).
+			Where("status = ?", KYC_VERIFIED).
+			Update()
+		if updateErr != nil {
+			return nil, updateErr
+		}
+
+		_, updateErr = tx.Model((*Customer)(nil)).
+			Set("kyc_verified_at = ?", now).
+			Where("id = ?", record.CustomerID).
+			Update()
+		if updateErr != nil {
+			return nil, updateErr
+		}
+	}
+
+	_, updateErr := tx.Model(&record).
+		Column("status", "review_note", "reviewed_by", "reviewed_at", "expires_on").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	auditErr := RecordAudit(tx, "customer", record.CustomerID, "kyc_review", actor, map[string]interface{}{
+		"kyc_record_id": record.ID,
+		"document_type": record.DocumentType,
+		"status":        status,
+		"note":          note,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	if status == KYC_VERIFIED {
+		if err := releaseKYCFreeze(tx, record.CustomerID, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	return &record, nil
+}
+
+// releaseKYCFreeze lifts the block on the accounts of a customer frozen for
+// want of KYC, or for fresh KYC after majority, once every holder of each
+// is verified.
+func releaseKYCFreeze(tx *pg.Tx, customerID uint, actor string) error {
+	var accountIDs []uint
+	getErr := tx.Model((*Account)(nil)).
+		Column("id").
+		Where("block_reason IN (?)", pg.In([]string{KYC_NOT_VERIFIED, MAJORITY_KYC_PENDING})).
+		Where("id IN (SELECT account_id FROM customer_to_accounts WHERE customer_id = ? AND "+holderRoles+")", customerID).
+		Select(&accountIDs)
+	if getErr != nil {
+		return getErr
+	}
+
+	for _, accountID := range accountIDs {
+		if err := checkHoldersVerified(tx, accountID); err != nil {
+			continue
+		}
+
+		account, err := lockAccount(tx, "id = ?", accountID)
+		if err != nil {
+			return err
+		}
+		if _, err := releaseBlock(tx, account, "holder KYC verified", actor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TrackReKYC tells branches about customers whose KYC falls due within
+// KYC_REMINDER_DAYS, and expires the KYC of those whose due date has
+// passed, freezing debits on their accounts until it is renewed. Holders
+// with no verified or pending KYC at all, such as customers from before KYC
+// was recorded, are frozen the same way. A record or customer that fails is
+// logged and the rest are still tracked.
+func TrackReKYC() error {
+	unverifiedErr := freezeUnverifiedHolders()
+
+	today := dateOf(time.Now())
+
+	var due []KYCRecord
+	getErr := database.Db.Model(&due).
+		Relation("Customer").
+		Where("kyc_record.status = ?", KYC_VERIFIED).
+		Where("kyc_record.expires_on <= ?", today.AddDate(0, 0, KYC_REMINDER_DAYS)).
+		Order("kyc_record.id").
+		Select()
+	if getErr != nil {
+		return getErr
+	}
+
+	failed := 0
+	for i := range due {
+		record := &due[i]
+		var err error
+		if !record.ExpiresOn.After(today) {
+			err = expireKYC(record)
+		} else if record.RemindedAt.IsZero() {
+			err = remindReKYC(record)
+		}
+		if err != nil {
+			log.Printf("re-KYC of KYC record %d failed: %s\n", record.ID, err.Error())
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d due KYC records failed", failed, len(due))
+	}
+	return unverifiedErr
+}
+
+func remindReKYC(record *KYCRecord) error {
+	message := fmt.Sprintf("Re-KYC of customer %d (%s) is due on %s.",
+		record.CustomerID, record.Customer.Name, record.ExpiresOn.Format("2006-01-02"))
+	if err := notifyBranch(database.Db, record.Customer.BranchID, "Re-KYC due", message); err != nil {
+		return err
+	}
+
+	_, updateErr := database.Db.Model(record).
+		Set("reminded_at = ?", time.Now()).
+		WherePK().
+		Update()
+	return updateErr
+}
+
+func expireKYC(record *KYCRecord) error {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	err := expireKYCRecord(tx, record)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+func expireKYCRecord(tx *pg.Tx, record *KYCRecord) error {
+	result, updateErr := tx.Model(record).
+		Set("status = ?", KYC_EXPIRED).
+		WherePK().
+		Where("status = ?", KYC_VERIFIED).
+		Update()
+	if updateErr != nil {
+		return updateErr
+	}
+	if result.RowsAffected() == 0 {
+		return nil
+	}
+
+	frozen, err := freezeHolderDebits(tx, record.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("KYC of customer %d (%s) expired on %s. Debits are frozen on %d of their accounts until re-KYC is completed.",
+		record.CustomerID, record.Customer.Name, record.ExpiresOn.Format("2006-01-02"), frozen)
+	return notifyBranch(tx, record.Customer.BranchID, "KYC expired", message)
+}
+
+// freezeHolderDebits freezes debits on the accounts the customer holds for
+// want of KYC, totally on those already frozen for credits, and returns how
+// many it froze.
+func freezeHolderDebits(tx *pg.Tx, customerID uint) (int, error) {
+	var accountIDs []uint
+	getErr := tx.Model((*Account)(nil)).
+		Column("id").
+		Where("status IN (?)", pg.In([]string{ACCOUNT_ACTIVE, ACCOUNT_CREDIT_FROZEN})).
+		Where("id IN (SELECT account_id FROM customer_to_accounts WHERE customer_id = ? AND "+holderRoles+")", customerID).
+		Order("id").
+		Select(&accountIDs)
+	if getErr != nil {
+		return 0, getErr
+	}
+
+	frozen := 0
+	for _, accountID := range accountIDs {
+		account, err := lockAccount(tx, "id = ?", accountID)
+		if err != nil {
+			return 0, err
+		}
+
+		changed, err := freezeDebits(tx, account, KYC_NOT_VERIFIED, "system")
+		if err != nil {
+			return 0, err
+		}
+		if changed {
+			frozen++
+		}
+	}
+
+	return frozen, nil
+}
+
+// freezeUnverifiedHolders freezes debits on the accounts of living customers
+// who hold them without any verified or pending KYC record, and tells their
+// branch. A customer that fails is logged and the rest are still frozen.
+func freezeUnverifiedHolders() error {
+	var customers []Customer
+	getErr := database.Db.Model(&customers).
+		Where("customer.deceased_on IS NULL").
+		Where("(customer.merged_into IS NULL OR customer.merged_into = 0)").
+		Where("NOT EXISTS (SELECT 1 FROM kyc_records r WHERE r.customer_id = customer.id AND r.status IN (?))", pg.In([]string{KYC_VERIFIED, KYC_PENDING})).
+		Where("EXISTS (SELECT 1 FROM customer_to_accounts m JOIN accounts a ON a.id = m.account_id WHERE m.customer_id = customer.id AND "+holderRoles+" AND a.status IN (?))", pg.In([]string{ACCOUNT_ACTIVE, ACCOUNT_CREDIT_FROZEN})).
+		Order("customer.id").
+		Select()
+	if getErr != nil {
+		return getErr
+	}
+
+	failed := 0
+	for i := range customers {
+		if err := freezeUnverifiedHolder(&customers[i]); err != nil {
+			log.Printf("freezing the accounts of customer %d failed: %s\n", customers[i].ID, err.Error())
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d customers without KYC could not be frozen", failed, len(customers))
+	}
+	return nil
+}
+
+func freezeUnverifiedHolder(customer *Customer) error {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	err := freezeAccountsOf(tx, customer)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+func freezeAccountsOf(tx *pg.Tx, customer *Customer) error {
+	frozen, err := freezeHolderDebits(tx, customer.ID)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Customer %d (%s) has no KYC on record. Debits are frozen on %d of their accounts until KYC is completed.",
+		customer.ID, customer.Name, frozen)
+	return notifyBranch(tx, customer.BranchID, "KYC missing", message)
+}
+
+// FindAllReKYCDueByBranchID lists the customers of a branch whose KYC has
+// expired or falls due within KYC_REMINDER_DAYS, with the record concerned.
+func FindAllReKYCDueByBranchID(id uint) ([]KYCRecord, error) {
+	var records []KYCRecord
+	getErr := database.Db.Model(&records).
+		Relation("Customer").
+		Where("customer.branch_id = ?", id).
+		Where("kyc_record.status IN (?)", pg.In([]string{KYC_VERIFIED, KYC_EXPIRED})).
+		Where("kyc_record.expires_on <= ?", dateOf(time.Now()).AddDate(0, 0, KYC_REMINDER_DAYS)).
+		Where("NOT EXISTS (SELECT 1 FROM kyc_records r WHERE r.customer_id = kyc_record.customer_id AND r.id > kyc_record.id AND r.status IN (?))", pg.In([]string{KYC_VERIFIED, KYC_PENDING})).
+		Order("kyc_record.expires_on", "kyc_record.id").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return records, nil
+}
@@ -2,18 +2,25 @@ package models
 
 import (
 	"github.com/shouryagautam/bankdeploy/database"
-	"errors"
-
-	"github.com/google/uuid"
 )
 
 type CustomerToAccount struct{
 	ID uint
 	AccountID uint `pg:"on_delete:CASCADE"`
 	CustomerID uint `pg:"on_delete:CASCADE"`
+	Role string
+
+	// Nominee details. Nominees are not holders, so CustomerID stays empty
+	// for them; NomineeCustomerID links a nominee who is also a customer.
+	NomineeCustomerID uint `pg:"on_delete:SET NULL"`
+	Name string
+	Relationship string
+	DateOfBirth string
+	SharePercentage float64
 
 	Customer *Customer `pg:"rel:has-one"`
     Account  *Account  `pg:"rel:has-one"`
+	NomineeCustomer *Customer `pg:"rel:has-one"`
 }
 
 func (mapping *CustomerToAccount) Save() error {
@@ -27,31 +34,3 @@ func (mapping *CustomerToAccount) Save() error {
 }
 
 
-func DeleteNomineeFromAccountByID(accNumber uuid.UUID,id uint) error {
-
-	account,err := FindAccountByAccountNumber(accNumber)
-
-	if err != nil {
-		return err
-	}
-
-    count, countErr := database.Db.Model((*CustomerToAccount)(nil)).
-        Where("account_id = ?", account.ID).
-        Count()
-    if countErr != nil {
-        return countErr
-    }
-
-    // Check if there is only one customer mapped to the account
-    if count <= 1 {
-        return errors.New("the account must have atleast one customer")
-    }
-
-	var mapping CustomerToAccount
-	_, deleteErr := database.Db.Model(&mapping).Where("customer_id=?",id).Where("account_id=?",account.ID).Delete()
-	if deleteErr != nil {
-		return deleteErr
-	}
-
-	return nil
-}
@@ -0,0 +1,407 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	MERGE_KEEP_SURVIVOR = "survivor"
+	MERGE_TAKE_MERGED   = "merged"
+)
+
+const (
+	MAPPING_MOVED    = "moved"
+	MAPPING_COMBINED = "combined"
+)
+
+// mergeField is a customer field that may differ between the two records
+// being merged.
+type mergeField struct {
+	name  string
+	value func(customer *Customer) string
+	take  func(survivor *Customer, merged *Customer)
+}
+
+var mergeFields = []mergeField{
+	{"name", func(c *Customer) string { return c.Name }, func(s *Customer, m *Customer) { s.Name = m.Name }},
+	{"pan", func(c *Customer) string { return c.PAN }, func(s *Customer, m *Customer) { s.PAN = m.PAN }},
+	{"dob", func(c *Customer) string {
+		if len(c.DOB) < 10 {
+			return c.DOB
+		}
+		return c.DOB[:10]
+	}, func(s *Customer, m *Customer) { s.DOB, s.Age = m.DOB, m.Age }},
+	{"phone", func(c *Customer) string {
+		if c.Phone == 0 {
+			return ""
+		}
+		return fmt.Sprint(c.Phone)
+	}, func(s *Customer, m *Customer) { s.Phone = m.Phone }},
+	{"address", func(c *Customer) string { return c.Address }, func(s *Customer, m *Customer) { s.Address = m.Address }},
+	{"branch_id", func(c *Customer) string { return fmt.Sprint(c.BranchID) }, func(s *Customer, m *Customer) { s.BranchID = m.BranchID }},
+}
+
+type FieldConflict struct {
+	Field    string
+	Survivor string
+	Merged   string
+	Choice   string
+}
+
+// MappingChange is a link of the merged customer to an account: moved to
+// the survivor, or combined into the survivor's own link to the account.
+type MappingChange struct {
+	MappingID     uint
+	AccountID     uint
+	Role          string
+	Action        string
+	IntoMappingID uint
+}
+
+// CustomerMerge describes a merge of one customer into another. A preview
+// is the same merge rolled back, so it shows exactly what would change.
+type CustomerMerge struct {
+	Preview           bool
+	Survivor          *Customer
+	Merged            *Customer
+	Conflicts         []FieldConflict
+	Mappings          []MappingChange
+	KYCRecordIDs      []uint
+	VPAIDs            []uint
+	TDSLedgerEntryIDs []uint
+	CardIDs           []uint
+	ChequeBookIDs     []uint
+	TDSExemptionIDs   []uint
+	DroppedExemptions []uint
+}
+
+// mappingKind groups the roles that cannot be combined with one another.
+func mappingKind(role string) string {
+	if role == ROLE_NOMINEE || role == ROLE_GUARDIAN {
+		return role
+	}
+	return "holder"
+}
+
+// holderRank orders holder roles so that combining two links keeps the
+// stronger one.
+func holderRank(role string) int {
+	if role == ROLE_PRIMARY {
+		return 2
+	}
+	return 1
+}
+
+// MergeCustomers merges the duplicate record mergedID into survivorID. The
+// accounts, nominations, KYC documents, VPAs and tax records of the merged
+// customer move to the survivor, fields that differ take the value chosen
+// for them in choices, and the merged customer is left as a tombstone
+// pointing at the survivor. Cash transaction reports stay with the
+// tombstone as filed. With preview set nothing is saved.
+func MergeCustomers(survivorID uint, mergedID uint, choices map[string]string, actor string, preview bool) (*CustomerMerge, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	merge, err := mergeCustomers(tx, survivorID, mergedID, choices, actor, preview)
+	if err != nil || preview {
+		tx.Rollback()
+		return merge, err
+	}
+
+	tx.Commit()
+	return merge, nil
+}
+
+func lockCustomer(tx *pg.Tx, id uint) (*Customer, error) {
+	var customer Customer
+	getErr := tx.Model(&customer).Where("id = ?", id).For("UPDATE").Select()
+	if getErr == pg.ErrNoRows {
+		return nil, fmt.Errorf("customer %d does not exist", id)
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if err := customer.CheckNotMerged(); err != nil {
+		return nil, err
+	}
+	if !customer.DeceasedOn.IsZero() {
+		return nil, fmt.Errorf("customer %d is deceased", id)
+	}
+
+	return &customer, nil
+}
+
+func mergeCustomers(tx *pg.Tx, survivorID uint, mergedID uint, choices map[string]string, actor string, preview bool) (*CustomerMerge, error) {
+	if survivorID == mergedID {
+		return nil, errors.New("a customer cannot be merged into itself")
+	}
+
+	survivor, err := lockCustomer(tx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := lockCustomer(tx, mergedID)
+	if err != nil {
+		return nil, err
+	}
+
+	if survivor.BankID != merged.BankID {
+		return nil, errors.New("customers of different banks cannot be merged")
+	}
+
+	pending, err := tx.Model((*PendingInstruction)(nil)).
+		Where("status = ?", INSTRUCTION_PENDING).
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("initiated_by = ?", mergedID).
+				WhereOr("id IN (SELECT pending_instruction_id FROM instruction_approvals WHERE customer_id = ?)", mergedID), nil
+		}).
+		Exists()
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, errors.New("the merged customer has joint instructions pending; wait for them to be approved, rejected or expire")
+	}
+
+	merge := CustomerMerge{Preview: preview}
+
+	// Fields are resolved first so that a preview lists every conflict.
+	var unresolved []string
+	for _, field := range mergeFields {
+		survivorValue, mergedValue := field.value(survivor), field.value(merged)
+		if survivorValue == mergedValue || mergedValue == "" {
+			continue
+		}
+		if survivorValue == "" {
+			field.take(survivor, merged)
+			continue
+		}
+
+		conflict := FieldConflict{Field: field.name, Survivor: survivorValue, Merged: mergedValue, Choice: choices[field.name]}
+		switch conflict.Choice {
+		case MERGE_TAKE_MERGED:
+			field.take(survivor, merged)
+		case MERGE_KEEP_SURVIVOR:
+		default:
+			unresolved = append(unresolved, field.name)
+		}
+		merge.Conflicts = append(merge.Conflicts, conflict)
+	}
+	if len(unresolved) > 0 && !preview {
+		return &merge, fmt.Errorf("choose survivor or merged for: %s", strings.Join(unresolved, ", "))
+	}
+	if merged.KYCVerifiedAt.After(survivor.KYCVerifiedAt) {
+		survivor.KYCVerifiedAt = merged.KYCVerifiedAt
+	}
+
+	var clashes []uint
+	_, err = tx.Query(&clashes, `
+		SELECT account_id FROM customer_to_accounts
+		WHERE customer_id IN (?, ?) OR nominee_customer_id IN (?, ?)
+		GROUP BY account_id
+		HAVING count(DISTINCT CASE WHEN role IN ('nominee', 'guardian') THEN role ELSE 'holder' END) > 1
+		ORDER BY account_id`, survivorID, mergedID, survivorID, mergedID)
+	if err != nil {
+		return nil, err
+	}
+	if len(clashes) > 0 {
+		return nil, fmt.Errorf("the customers are linked to accounts %v in different roles, such as holder and nominee", clashes)
+	}
+
+	if err := moveMappings(tx, survivorID, mergedID, &merge); err != nil {
+		return nil, err
+	}
+
+	if err := moveCustomerRecords(tx, survivorID, mergedID, &merge); err != nil {
+		return nil, err
+	}
+
+	// Tombstone the merged customer first, freeing its PAN for the survivor.
+	now := time.Now()
+	_, updateErr := tx.Model(merged).
+		Set("merged_into = ?", survivorID).
+		Set("merged_at = ?", now).
+		Set("pan = NULL").
+		WherePK().
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	if err := survivor.setBank(tx); err != nil {
+		return nil, err
+	}
+	_, updateErr = tx.Model(survivor).
+		Column("name", "pan", "dob", "age", "phone", "address", "branch_id", "bank_id", "kyc_verified_at").
+		WherePK().
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return nil, uniqueViolation(updateErr)
+	}
+
+	if err := releaseKYCFreeze(tx, survivorID, actor); err != nil {
+		return nil, err
+	}
+
+	merge.Survivor, merge.Merged = survivor, merged
+
+	auditErr := RecordAudit(tx, "customer", survivorID, "merge", actor, map[string]interface{}{
+		"merged_customer_id":   mergedID,
+		"conflicts":            merge.Conflicts,
+		"mappings":             merge.Mappings,
+		"kyc_record_ids":       merge.KYCRecordIDs,
+		"vpa_ids":              merge.VPAIDs,
+		"tds_ledger_entry_ids": merge.TDSLedgerEntryIDs,
+		"card_ids":             merge.CardIDs,
+		"cheque_book_ids":      merge.ChequeBookIDs,
+		"tds_exemption_ids":    merge.TDSExemptionIDs,
+		"dropped_exemptions":   merge.DroppedExemptions,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	auditErr = RecordAudit(tx, "customer", mergedID, "merged_into", actor, map[string]interface{}{
+		"survivor_customer_id": survivorID,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return &merge, nil
+}
+
+// moveMappings moves the account links and nominations of the merged
+// customer to the survivor. Where the survivor already has a link of the
+// same kind to the account the two are combined: holders keep the stronger
+// role, a nominee's shares are added up and a duplicate guardian is dropped.
+func moveMappings(tx *pg.Tx, survivorID uint, mergedID uint, merge *CustomerMerge) error {
+	var mappings []CustomerToAccount
+	getErr := tx.Model(&mappings).
+		Where("customer_id = ? OR nominee_customer_id = ?", mergedID, mergedID).
+		Order("id").
+		For("UPDATE").
+		Select()
+	if getErr != nil {
+		return getErr
+	}
+
+	for i := range mappings {
+		mapping := &mappings[i]
+		nominee := mapping.Role == ROLE_NOMINEE
+
+		counterpart := CustomerToAccount{}
+		query := tx.Model(&counterpart).Where("account_id = ?", mapping.AccountID)
+		if nominee {
+			query = query.Where("nominee_customer_id = ?", survivorID).Where("role = ?", ROLE_NOMINEE)
+		} else {
+			query = query.Where("customer_id = ?", survivorID)
+		}
+		getErr := query.For("UPDATE").Select()
+		if getErr != nil && getErr != pg.ErrNoRows {
+			return getErr
+		}
+
+		change := MappingChange{MappingID: mapping.ID, AccountID: mapping.AccountID, Role: mapping.Role}
+		var updateErr error
+		if getErr == pg.ErrNoRows {
+			change.Action = MAPPING_MOVED
+			column := "customer_id"
+			if nominee {
+				column = "nominee_customer_id"
+			}
+			_, updateErr = tx.Model(mapping).Set(column+" = ?", survivorID).WherePK().Update()
+		} else {
+			change.Action, change.IntoMappingID = MAPPING_COMBINED, counterpart.ID
+			switch {
+			case nominee:
+				_, updateErr = tx.Model(&counterpart).
+					Set("share_percentage = share_percentage + ?", mapping.SharePercentage).
+					WherePK().
+					Update()
+			case mappingKind(mapping.Role) == "holder" && holderRank(mapping.Role) > holderRank(counterpart.Role):
+				_, updateErr = tx.Model(&counterpart).Set("role = ?", mapping.Role).WherePK().Update()
+			}
+			if updateErr == nil {
+				_, updateErr = tx.Model((*VPA)(nil)).
+					Set("customer_to_account_id = ?", counterpart.ID).
+					Where("customer_to_account_id = ?", mapping.ID).
+					Update()
+			}
+			if updateErr == nil {
+				_, updateErr = tx.Model(mapping).WherePK().Delete()
+			}
+		}
+		if updateErr != nil {
+			return updateErr
+		}
+
+		merge.Mappings = append(merge.Mappings, change)
+	}
+
+	return nil
+}
+
+// moveCustomerRecords moves the KYC documents, VPAs, cards, cheque books and
+// tax records of the merged customer to the survivor. Of the KYC documents only the latest
+// verification stays verified; exemptions for years the survivor already
+// has one for are dropped.
+func moveCustomerRecords(tx *pg.Tx, survivorID uint, mergedID uint, merge *CustomerMerge) error {
+	moves := []struct {
+		model interface{}
+		ids   *[]uint
+	}{
+		{(*KYCRecord)(nil), &merge.KYCRecordIDs},
+		{(*VPA)(nil), &merge.VPAIDs},
+		{(*TDSLedgerEntry)(nil), &merge.TDSLedgerEntryIDs},
+		{(*Card)(nil), &merge.CardIDs},
+		{(*ChequeBook)(nil), &merge.ChequeBookIDs},
+	}
+	for _, move := range moves {
+		_, updateErr := tx.Model(move.model).
+			Set("customer_id = ?", survivorID).
+			Where("customer_id = ?", mergedID).
+			Returning("id").
+			Update(move.ids)
+		if updateErr != nil {
+			return updateErr
+		}
+	}
+
+	_, updateErr := tx.Model((*KYCRecord)(nil)).
+		Set("status = ?", KYC_EXPIRED).
+		Where("customer_id = ?", survivorID).
+		Where("status = ?", KYC_VERIFIED).
+		Where("id <> (SELECT id FROM kyc_records WHERE customer_id = ? AND status = ? ORDER BY expires_on DESC, id DESC LIMIT 1)", survivorID, KYC_VERIFIED).
+		Update()
+	if updateErr != nil {
+		return updateErr
+	}
+
+	_, deleteErr := tx.Model((*TDSExemption)(nil)).
+		Where("customer_id = ?", mergedID).
+		Where("financial_year IN (SELECT financial_year FROM tds_exemptions WHERE customer_id = ?)", survivorID).
+		Returning("id").
+		Delete(&merge.DroppedExemptions)
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	_, updateErr = tx.Model((*TDSExemption)(nil)).
+		Set("customer_id = ?", survivorID).
+		Where("customer_id = ?", mergedID).
+		Returning("id").
+		Update(&merge.TDSExemptionIDs)
+	return updateErr
+}
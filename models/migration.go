@@ -0,0 +1,169 @@
+package models
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+// columnMigrations bring tables created before a column was added to their
+// model up to date. CreateTable with IfNotExists leaves an existing table
+// as it is, so every column added to a model after its table first shipped
+// is added here, with existing rows backfilled where a zero value would be
+// wrong. Every statement can be run again without effect.
+var columnMigrations = []string{
+	// Accounts opened before statuses existed are active.
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status text DEFAULT '` + ACCOUNT_ACTIVE + `'`,
+	`UPDATE accounts SET status = '` + ACCOUNT_ACTIVE + `' WHERE status IS NULL OR status = ''`,
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status_reason text`,
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS opened_at timestamptz`,
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS dormant_since timestamptz`,
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS block_reason text`,
+	`UPDATE accounts SET block_reason = '` + DORMANCY_KYC_PENDING + `', dormant_since = COALESCE(dormant_since, now())
+		WHERE status = '` + ACCOUNT_DORMANT + `' AND block_reason IS NULL`,
+	`UPDATE accounts SET block_reason = status_reason
+		WHERE status_reason IN ('` + MAJORITY_KYC_PENDING + `', '` + KYC_NOT_VERIFIED + `') AND block_reason IS NULL`,
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS operating_mode text DEFAULT '` + MODE_EITHER_OR_SURVIVOR + `'`,
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS joint_approval_threshold double precision DEFAULT 0`,
+
+	// The opening balance of older accounts is not known, so they are
+	// reconciled from a snapshot of their balance as it is now instead.
+	// Their opening_balance stays NULL to tell them apart.
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS opening_balance double precision`,
+	`INSERT INTO balance_snapshots (account_id, balance, transaction_id, taken_at)
+		SELECT a.id, COALESCE(a.balance, 0),
+			COALESCE((SELECT max(t.id) FROM transactions t
+				WHERE t.account_id = a.id OR t.receiver_account_number = a.account_number), 0),
+			now()
+		FROM accounts a
+		WHERE a.opening_balance IS NULL
+		ON CONFLICT (account_id) DO NOTHING`,
+
+	`ALTER TABLE customers ADD COLUMN IF NOT EXISTS bank_id bigint`,
+	`UPDATE customers c SET bank_id = b.bank_id FROM branches b WHERE b.id = c.branch_id AND c.bank_id IS NULL`,
+	// PANs saved before NormalizePAN are normalised the same way, and blank
+	// ones cleared, so that the PAN index built by createPANIndex sees them
+	// as customers created now would be.
+	`UPDATE customers SET pan = NULLIF(upper(btrim(pan)), '')
+		WHERE pan IS DISTINCT FROM NULLIF(upper(btrim(pan)), '')`,
+	`ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_verified_at timestamptz`,
+	`ALTER TABLE customers ADD COLUMN IF NOT EXISTS deceased_on timestamptz`,
+	`ALTER TABLE customers ADD COLUMN IF NOT EXISTS merged_into bigint`,
+	`ALTER TABLE customers ADD COLUMN IF NOT EXISTS merged_at timestamptz`,
+
+	// Before roles, an account's first mapping was its owner. The other
+	// mappings of a joint account were its co-holders, and those of any
+	// other account the nominee given when it was opened.
+	`ALTER TABLE customer_to_accounts ADD COLUMN IF NOT EXISTS role text`,
+	`ALTER TABLE customer_to_accounts ADD COLUMN IF NOT EXISTS nominee_customer_id bigint REFERENCES customers (id) ON DELETE SET NULL`,
+	`ALTER TABLE customer_to_accounts ADD COLUMN IF NOT EXISTS name text`,
+	`ALTER TABLE customer_to_accounts ADD COLUMN IF NOT EXISTS relationship text`,
+	`ALTER TABLE customer_to_accounts ADD COLUMN IF NOT EXISTS date_of_birth text`,
+	`ALTER TABLE customer_to_accounts ADD COLUMN IF NOT EXISTS share_percentage double precision`,
+	`UPDATE customer_to_accounts m SET role = '` + ROLE_PRIMARY + `'
+		WHERE m.role IS NULL
+		AND m.id = (SELECT min(f.id) FROM customer_to_accounts f WHERE f.account_id = m.account_id)`,
+	`UPDATE customer_to_accounts m SET role = '` + ROLE_JOINT + `'
+		FROM accounts a
+		WHERE a.id = m.account_id AND a.account_type = 'joint' AND m.role IS NULL`,
+	`UPDATE customer_to_accounts m SET role = '` + ROLE_NOMINEE + `',
+			nominee_customer_id = m.customer_id,
+			customer_id = NULL,
+			name = c.name,
+			share_percentage = 100.0 / (SELECT count(*) FROM customer_to_accounts n
+				WHERE n.account_id = m.account_id AND n.role IS NULL)
+		FROM customers c
+		WHERE c.id = m.customer_id AND m.role IS NULL`,
+
+	`ALTER TABLE deceased_claims ADD COLUMN IF NOT EXISTS unsettled jsonb`,
+	`ALTER TABLE claim_payouts ADD COLUMN IF NOT EXISTS payout_mode text`,
+	`ALTER TABLE claim_payouts ADD COLUMN IF NOT EXISTS payout_account_number uuid`,
+
+	// Cards and cheque books issued before they named a holder belong to
+	// the guardian of a minor's account, or else its primary holder.
+	`ALTER TABLE cards ADD COLUMN IF NOT EXISTS customer_id bigint REFERENCES customers (id) ON DELETE RESTRICT`,
+	`ALTER TABLE cheque_books ADD COLUMN IF NOT EXISTS customer_id bigint REFERENCES customers (id) ON DELETE RESTRICT`,
+	`UPDATE cards c SET customer_id = (SELECT m.customer_id FROM customer_to_accounts m
+			WHERE m.account_id = c.account_id AND m.role IN ('` + ROLE_GUARDIAN + `', '` + ROLE_PRIMARY + `')
+			ORDER BY m.role = '` + ROLE_GUARDIAN + `' DESC LIMIT 1)
+		WHERE c.customer_id IS NULL`,
+	`UPDATE cheque_books b SET customer_id = (SELECT m.customer_id FROM customer_to_accounts m
+			WHERE m.account_id = b.account_id AND m.role IN ('` + ROLE_GUARDIAN + `', '` + ROLE_PRIMARY + `')
+			ORDER BY m.role = '` + ROLE_GUARDIAN + `' DESC LIMIT 1)
+		WHERE b.customer_id IS NULL`,
+
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS receiver_vpa text`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reference text`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS initiated_by bigint`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS value_date date`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS booking_date date`,
+	`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS override_by text`,
+	`UPDATE transactions SET value_date = time::date WHERE value_date IS NULL`,
+	`UPDATE transactions SET booking_date = time::date WHERE booking_date IS NULL`,
+}
+
+// MigrateColumns runs columnMigrations in order and stops at the first
+// that fails, then builds the PAN index.
+func MigrateColumns() error {
+	for _, statement := range columnMigrations {
+		if _, err := database.Db.Exec(statement); err != nil {
+			return fmt.Errorf("migration %q: %w", statement, err)
+		}
+	}
+	return createPANIndex()
+}
+
+// panDuplicate is a PAN held by more than one customer of a bank.
+type panDuplicate struct {
+	BankID      uint
+	PAN         string
+	CustomerIDs []uint `pg:",array"`
+	BranchID    uint
+}
+
+// createPANIndex makes PANs unique per bank. Data from before PANs were
+// checked may hold the same PAN more than once; the index is then left
+// out and the branch of each such customer is told to merge the
+// duplicates with MergeCustomers, which frees the merged record's PAN. The
+// index is built on the first start after the last duplicate is merged.
+func createPANIndex() error {
+	var duplicates []panDuplicate
+	_, err := database.Db.Query(&duplicates, `
+		SELECT bank_id, pan, array_agg(id ORDER BY id) AS customer_ids, min(branch_id) AS branch_id
+		FROM customers
+		WHERE pan IS NOT NULL
+		GROUP BY bank_id, pan
+		HAVING count(*) > 1`)
+	if err != nil {
+		return err
+	}
+
+	if len(duplicates) == 0 {
+		_, err := database.Db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS customers_bank_id_pan_key ON customers (bank_id, pan)`)
+		return err
+	}
+
+	for _, duplicate := range duplicates {
+		message := fmt.Sprintf("Customers %v share the PAN %s. Merge the duplicate records so that PANs can be made unique.",
+			duplicate.CustomerIDs, duplicate.PAN)
+
+		notified, err := database.Db.Model((*Notification)(nil)).
+			Where("branch_id = ?", duplicate.BranchID).
+			Where("message = ?", message).
+			Exists()
+		if err != nil {
+			return err
+		}
+		if notified {
+			continue
+		}
+
+		if err := notifyBranch(database.Db, duplicate.BranchID, "Duplicate PAN", message); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("PAN index not built: %d PANs are held by more than one customer of a bank\n", len(duplicates))
+	return nil
+}
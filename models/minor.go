@@ -0,0 +1,290 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const MAJORITY_AGE = 18
+
+const (
+	MINOR_TRANSACTION_LIMIT = 10000.00
+	MINOR_DAILY_LIMIT       = 25000.00
+)
+
+// MAJORITY_KYC_PENDING is the status reason of accounts frozen when their
+// minor holder comes of age.
+const MAJORITY_KYC_PENDING = "holder attained majority, fresh KYC pending"
+
+// Notification is a message for the staff of a branch.
+type Notification struct {
+	ID        uint
+	BranchID  uint    `pg:"on_delete:CASCADE"`
+	Branch    *Branch `pg:"rel:has-one"`
+	Subject   string
+	Message   string
+	CreatedAt time.Time
+}
+
+func notifyBranch(db orm.DB, branchID uint, subject string, message string) error {
+	notification := Notification{
+		BranchID:  branchID,
+		Subject:   subject,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+
+	_, insertErr := db.Model(&notification).Insert()
+	return insertErr
+}
+
+func FindAllNotificationsByBranchID(id uint) ([]Notification, error) {
+	var notifications []Notification
+	getErr := database.Db.Model(&notifications).
+		Where("branch_id = ?", id).
+		Order("id DESC").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return notifications, nil
+}
+
+// IsMinor reports whether the customer is below MAJORITY_AGE today.
+func (customer *Customer) IsMinor() (bool, error) {
+	if len(customer.DOB) < 10 {
+		return false, errors.New("customer has no valid date of birth")
+	}
+
+	dob, err := time.Parse("2006-01-02", customer.DOB[:10])
+	if err != nil {
+		return false, err
+	}
+
+	return time.Now().Before(dob.AddDate(MAJORITY_AGE, 0, 0)), nil
+}
+
+// CheckGuardian verifies that guardianID can operate accounts for the minor.
+func CheckGuardian(minor *Customer, guardianID uint) error {
+	if guardianID == 0 {
+		return errors.New("a guardian is required for a minor's account")
+	}
+	if guardianID == minor.ID {
+		return errors.New("a minor cannot be their own guardian")
+	}
+
+	guardian, err := FindCustomerByID(guardianID)
+	if err != nil {
+		return err
+	}
+
+	if err := guardian.CheckNotMerged(); err != nil {
+		return err
+	}
+	if !guardian.DeceasedOn.IsZero() {
+		return errors.New("guardian is deceased")
+	}
+
+	minorGuardian, err := guardian.IsMinor()
+	if err != nil {
+		return err
+	}
+	if minorGuardian {
+		return errors.New("guardian must be an adult")
+	}
+
+	return nil
+}
+
+// accountGuardianID returns the guardian operating a minor's account, or 0.
+func accountGuardianID(db orm.DB, accountID uint) (uint, error) {
+	var mapping CustomerToAccount
+	getErr := db.Model(&mapping).
+		Where("account_id = ?", accountID).
+		Where("role = ?", ROLE_GUARDIAN).
+		Limit(1).
+		Select()
+	if getErr == pg.ErrNoRows {
+		return 0, nil
+	}
+	if getErr != nil {
+		return 0, getErr
+	}
+
+	return mapping.CustomerID, nil
+}
+
+// checkMinorDebit allows only the guardian to debit a minor's account, within
+// the per-transaction and daily limits for minors. Card authorizations still
+// on hold count towards the daily limit.
+func checkMinorDebit(db orm.DB, guardianID uint, accountID uint, initiatedBy uint, amount float64) error {
+	if initiatedBy != guardianID {
+		return errors.New("only the guardian can operate a minor's account")
+	}
+
+	if amount > MINOR_TRANSACTION_LIMIT {
+		return fmt.Errorf("minor accounts are limited to %v per transaction", MINOR_TRANSACTION_LIMIT)
+	}
+
+	var debited, held float64
+	err := db.Model((*Transaction)(nil)).
+		ColumnExpr("coalesce(sum(amount), 0)").
+		Where("account_id = ?", accountID).
+		Where("type_of_transaction IN (?)", pg.In([]string{TRANSACTION_WITHDRAW, TRANSACTION_TRANSFER})).
+		Where("time >= date_trunc('day', now())").
+		Select(pg.Scan(&debited))
+	if err != nil {
+		return err
+	}
+
+	err = db.Model((*Hold)(nil)).
+		ColumnExpr("coalesce(sum(amount), 0)").
+		Where("account_id = ?", accountID).
+		Where("status = ?", HOLD_ACTIVE).
+		Where("source_reference LIKE 'CARD-%'").
+		Where("placed_at >= date_trunc('day', now())").
+		Select(pg.Scan(&held))
+	if err != nil {
+		return err
+	}
+
+	if debited+held+amount > MINOR_DAILY_LIMIT {
+		return fmt.Errorf("minor accounts are limited to %v of debits a day", MINOR_DAILY_LIMIT)
+	}
+
+	return nil
+}
+
+// ConvertMajorAccounts finds minors' accounts whose holder has turned
+// MAJORITY_AGE. The guardian's rights are removed, debits are frozen until
+// the now-adult holder completes fresh KYC, totally if the account cannot
+// be frozen for debits alone, and the branch is notified.
+func ConvertMajorAccounts() error {
+	var guardians []CustomerToAccount
+	getErr := database.Db.Model(&guardians).
+		Where("role = ?", ROLE_GUARDIAN).
+		Where(`account_id IN (SELECT m.account_id FROM customer_to_accounts m
+			JOIN customers c ON c.id = m.customer_id
+			WHERE m.role = ? AND c.dob <= ?)`, ROLE_PRIMARY, time.Now().AddDate(-MAJORITY_AGE, 0, 0)).
+		Select()
+
+	if getErr != nil {
+		return getErr
+	}
+
+	for _, guardian := range guardians {
+		if err := convertMajorAccount(guardian); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convertMajorAccount(guardian CustomerToAccount) error {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	account, err := lockAccount(tx, "id = ?", guardian.AccountID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, deleteErr := tx.Model(&guardian).WherePK().Delete()
+	if deleteErr != nil {
+		tx.Rollback()
+		return deleteErr
+	}
+
+	auditErr := RecordAudit(tx, "account", account.ID, "guardian_removed", "system", map[string]interface{}{
+		"guardian_id": guardian.CustomerID,
+	})
+	if auditErr != nil {
+		tx.Rollback()
+		return auditErr
+	}
+
+	frozen, err := freezeDebits(tx, account, MAJORITY_KYC_PENDING, "system")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// An account already frozen keeps its status, but is still blocked so
+	// that lifting that freeze does not let the new major operate it
+	// before fresh KYC.
+	if !frozen && account.BlockReason == "" {
+		_, updateErr := tx.Model(account).
+			Set("block_reason = ?", MAJORITY_KYC_PENDING).
+			WherePK().
+			Update()
+		if updateErr != nil {
+			tx.Rollback()
+			return updateErr
+		}
+
+		auditErr := RecordAudit(tx, "account", account.ID, "block_placed", "system", map[string]interface{}{
+			"status": account.Status,
+			"reason": MAJORITY_KYC_PENDING,
+		})
+		if auditErr != nil {
+			tx.Rollback()
+			return auditErr
+		}
+	}
+
+	message := fmt.Sprintf("The holder of account %s has attained majority. The guardian has been removed and debits are frozen until fresh KYC is completed.", account.AccountNumber)
+	if err := notifyBranch(tx, account.BranchID, "Minor account attained majority", message); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// CompleteMajorityKYC lifts the debit freeze placed by ConvertMajorAccounts
+// once every holder, the now-adult one included, has a verified KYC record.
+// Verifying the record through ReviewKYC lifts it as well.
+func CompleteMajorityKYC(accountID uint, actor string) (*Account, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	account, err := completeMajorityKYC(tx, accountID, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return account, nil
+}
+
+func completeMajorityKYC(tx *pg.Tx, accountID uint, actor string) (*Account, error) {
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.BlockReason != MAJORITY_KYC_PENDING {
+		return nil, errors.New("account is not awaiting KYC after majority")
+	}
+
+	if err := checkHoldersVerified(tx, account.ID); err != nil {
+		return nil, err
+	}
+
+	return releaseBlock(tx, account, "fresh KYC completed after majority", actor)
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	MIS_DEFAULT_TOP = 10
+	MIS_MAX_TOP     = 100
+)
+
+// misDailyTransactions pre-aggregates transaction volumes per branch, day,
+// type and mode so that dashboards do not scan the transactions table. It
+// is refreshed by end of day and a background job, so the current day lags
+// by up to the refresh interval.
+const misDailyTransactions = "mis_daily_transactions"
+
+// CreateMISViews creates the materialized views behind the dashboards. The
+// unique index lets them be refreshed without blocking readers.
+func CreateMISViews() error {
+	_, err := database.Db.Exec(`
+		CREATE MATERIALIZED VIEW IF NOT EXISTS ` + misDailyTransactions + ` AS
+		SELECT a.branch_id,
+			t.time::date AS day,
+			COALESCE(t.type_of_transaction, '') AS type_of_transaction,
+			COALESCE(t.mode_of_payment, '') AS mode_of_payment,
+			count(*) AS count,
+			COALESCE(sum(t.amount), 0) AS value
+		FROM transactions t JOIN accounts a ON a.id = t.account_id
+		GROUP BY 1, 2, 3, 4`)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.Db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS ` + misDailyTransactions + `_key
+		ON ` + misDailyTransactions + ` (branch_id, day, type_of_transaction, mode_of_payment)`)
+	return err
+}
+
+func RefreshMISViews() error {
+	_, err := database.Db.Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY " + misDailyTransactions)
+	return err
+}
+
+// MISScope selects the accounts a dashboard covers: one branch, every
+// branch of one bank, or the whole system when both ids are zero.
+type MISScope struct {
+	BranchID uint
+	BankID   uint
+}
+
+// condition matches the rows of alias, which must have a branch_id column,
+// that fall in the scope.
+func (scope MISScope) condition(alias string) *orm.SafeQueryAppender {
+	switch {
+	case scope.BranchID != 0:
+		return pg.SafeQuery(alias+".branch_id = ?", scope.BranchID)
+	case scope.BankID != 0:
+		return pg.SafeQuery(alias+".branch_id IN (SELECT id FROM branches WHERE bank_id = ?)", scope.BankID)
+	}
+	return pg.SafeQuery("TRUE")
+}
+
+type ProductDeposits struct {
+	AccountType  string
+	Accounts     int
+	TotalBalance float64
+}
+
+type DailyCount struct {
+	Day   time.Time
+	Count int
+}
+
+type TransactionVolume struct {
+	Day               time.Time
+	TypeOfTransaction string
+	ModeOfPayment     string
+	Count             int
+	Value             float64
+}
+
+type TopAccount struct {
+	AccountID     uint
+	AccountNumber string
+	AccountType   string
+	BranchID      uint
+	Balance       float64
+}
+
+type MISDashboard struct {
+	Scope              MISScope
+	From               time.Time
+	To                 time.Time
+	TotalDeposits      float64
+	DepositsByProduct  []ProductDeposits
+	Openings           []DailyCount
+	Closures           []DailyCount
+	TransactionVolumes []TransactionVolume
+	TopAccounts        []TopAccount
+}
+
+// FindMISDashboard aggregates the accounts and transactions of a scope. The
+// days from and to are both included; top is the number of accounts with
+// the largest balances to list.
+func FindMISDashboard(scope MISScope, from time.Time, to time.Time, top int) (*MISDashboard, error) {
+	if scope.BranchID != 0 {
+		if _, err := FindBranchByID(scope.BranchID); err != nil {
+			return nil, err
+		}
+	} else if scope.BankID != 0 {
+		if _, err := FindBankByID(scope.BankID); err != nil {
+			return nil, err
+		}
+	}
+
+	if top <= 0 {
+		top = MIS_DEFAULT_TOP
+	}
+	if top > MIS_MAX_TOP {
+		top = MIS_MAX_TOP
+	}
+
+	dashboard := MISDashboard{Scope: scope, From: dateOf(from), To: dateOf(to)}
+	end := dashboard.To.AddDate(0, 0, 1)
+	accounts := scope.condition("a")
+
+	_, err := database.Db.Query(&dashboard.DepositsByProduct, `
+		SELECT COALESCE(a.account_type, '') AS account_type, count(*) AS accounts, COALESCE(sum(a.balance), 0) AS total_balance
+		FROM accounts a
+		WHERE ? AND a.status IS DISTINCT FROM ?
+		GROUP BY 1 ORDER BY 3 DESC`, accounts, ACCOUNT_CLOSED)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range dashboard.DepositsByProduct {
+		dashboard.TotalDeposits += product.TotalBalance
+	}
+	dashboard.TotalDeposits = roundAmount(dashboard.TotalDeposits)
+
+	_, err = database.Db.Query(&dashboard.Openings, `
+		SELECT a.opened_at::date AS day, count(*) AS count
+		FROM accounts a
+		WHERE ? AND a.opened_at >= ?::date AND a.opened_at < ?::date
+		GROUP BY 1 ORDER BY 1`, accounts, dashboard.From, end)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = database.Db.Query(&dashboard.Closures, `
+		SELECT l.time::date AS day, count(*) AS count
+		FROM audit_logs l JOIN accounts a ON a.id = l.entity_id
+		WHERE l.entity = 'account' AND l.action = 'status_change' AND l.details->>'to' = ?
+			AND ? AND l.time >= ?::date AND l.time < ?::date
+		GROUP BY 1 ORDER BY 1`, ACCOUNT_CLOSED, accounts, dashboard.From, end)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = database.Db.Query(&dashboard.TransactionVolumes, `
+		SELECT v.day, v.type_of_transaction, v.mode_of_payment, sum(v.count) AS count, sum(v.value) AS value
+		FROM `+misDailyTransactions+` v
+		WHERE ? AND v.day >= ?::date AND v.day < ?::date
+		GROUP BY 1, 2, 3 ORDER BY 1, 2, 3`, scope.condition("v"), dashboard.From, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var topAccounts []Account
+	err = database.Db.Model(&topAccounts).
+		Column("account.id", "account.account_number", "account.account_type", "account.branch_id", "account.balance").
+		Where("?", scope.condition("account")).
+		Where("account.status IS DISTINCT FROM ?", ACCOUNT_CLOSED).
+		OrderExpr("account.balance DESC NULLS LAST").
+		Order("account.id").
+		Limit(top).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range topAccounts {
+		dashboard.TopAccounts = append(dashboard.TopAccounts, TopAccount{
+			AccountID:     account.ID,
+			AccountNumber: MaskAccountNumber(account.AccountNumber),
+			AccountType:   account.AccountType,
+			BranchID:      account.BranchID,
+			Balance:       account.Balance,
+		})
+	}
+
+	return &dashboard, nil
+}
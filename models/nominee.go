@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	ROLE_PRIMARY  = "primary"
+	ROLE_JOINT    = "joint"
+	ROLE_NOMINEE  = "nominee"
+	ROLE_GUARDIAN = "guardian"
+)
+
+// holderRoles matches the mappings of account holders. Mappings created
+// before roles existed have no role and are treated as holders.
+const holderRoles = "(role IS NULL OR role NOT IN ('nominee', 'guardian'))"
+
+// SetNominees replaces the nominees of an account. The shares of the new
+// nominees must add up to 100 percent; an empty list removes all nominees.
+func SetNominees(accNumber uuid.UUID, nominees []CustomerToAccount) ([]CustomerToAccount, error) {
+	total := 0.0
+	for _, nominee := range nominees {
+		if nominee.SharePercentage <= 0 {
+			return nil, errors.New("every nominee must have a positive share")
+		}
+		total += nominee.SharePercentage
+	}
+	if len(nominees) > 0 && math.Abs(total-100) > 0.001 {
+		return nil, fmt.Errorf("nominee shares add up to %v%%, not 100%%", total)
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	saved, err := setNominees(tx, accNumber, nominees)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return saved, nil
+}
+
+func setNominees(tx *pg.Tx, accNumber uuid.UUID, nominees []CustomerToAccount) ([]CustomerToAccount, error) {
+	account, err := lockAccount(tx, "account_number = ?", accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Status == ACCOUNT_CLOSED {
+		return nil, errors.New("account is closed")
+	}
+
+	holders, err := accountHolderIDs(tx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, deleteErr := tx.Model((*CustomerToAccount)(nil)).
+		Where("account_id = ?", account.ID).
+		Where("role = ?", ROLE_NOMINEE).
+		Delete()
+	if deleteErr != nil {
+		return nil, deleteErr
+	}
+
+	for i := range nominees {
+		nominee := &nominees[i]
+
+		if nominee.NomineeCustomerID != 0 {
+			if containsID(holders, nominee.NomineeCustomerID) {
+				return nil, errors.New("an account holder cannot be a nominee of the same account")
+			}
+
+			var customer Customer
+			getErr := tx.Model(&customer).Where("id = ?", nominee.NomineeCustomerID).Select()
+			if getErr == pg.ErrNoRows {
+				return nil, errors.New("nominee customer does not exist")
+			}
+			if getErr != nil {
+				return nil, getErr
+			}
+			if err := customer.CheckNotMerged(); err != nil {
+				return nil, err
+			}
+
+			if nominee.Name == "" {
+				nominee.Name = customer.Name
+			}
+			if nominee.DateOfBirth == "" {
+				nominee.DateOfBirth = customer.DOB
+			}
+		}
+
+		if nominee.Name == "" || nominee.Relationship == "" || nominee.DateOfBirth == "" {
+			return nil, errors.New("every nominee needs a name, relationship and date of birth")
+		}
+
+		nominee.ID = 0
+		nominee.AccountID = account.ID
+		nominee.CustomerID = 0
+		nominee.Role = ROLE_NOMINEE
+
+		_, insertErr := tx.Model(nominee).Returning("*").Insert()
+		if insertErr != nil {
+			return nil, insertErr
+		}
+	}
+
+	return nominees, nil
+}
+
+func FindAllNomineesByAccountNumber(accNumber uuid.UUID) ([]CustomerToAccount, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var nominees []CustomerToAccount
+	getErr := database.Db.Model(&nominees).
+		Relation("NomineeCustomer").
+		Where("customer_to_account.account_id = ?", account.ID).
+		Where("customer_to_account.role = ?", ROLE_NOMINEE).
+		Order("customer_to_account.id").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return nominees, nil
+}
+
+// DeleteNomineeFromAccountByID removes one nominee and scales the shares of
+// the remaining nominees up so that they still add up to 100 percent.
+func DeleteNomineeFromAccountByID(accNumber uuid.UUID, id uint) error {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return txErr
+	}
+
+	err := deleteNominee(tx, accNumber, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+func deleteNominee(tx *pg.Tx, accNumber uuid.UUID, id uint) error {
+	account, err := lockAccount(tx, "account_number = ?", accNumber)
+	if err != nil {
+		return err
+	}
+
+	var nominee CustomerToAccount
+	deleteResult, deleteErr := tx.Model(&nominee).
+		Where("id = ?", id).
+		Where("account_id = ?", account.ID).
+		Where("role = ?", ROLE_NOMINEE).
+		Returning("*").
+		Delete()
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	if deleteResult.RowsAffected() == 0 {
+		return errors.New("nominee does not exist on this account")
+	}
+
+	remaining := 100 - nominee.SharePercentage
+	if remaining <= 0 {
+		return nil
+	}
+
+	_, updateErr := tx.Model((*CustomerToAccount)(nil)).
+		Set("share_percentage = share_percentage * 100 / ?", remaining).
+		Where("account_id = ?", account.ID).
+		Where("role = ?", ROLE_NOMINEE).
+		Update()
+
+	return updateErr
+}
@@ -0,0 +1,477 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	MODE_EITHER_OR_SURVIVOR = "either_or_survivor"
+	MODE_FORMER_OR_SURVIVOR = "former_or_survivor"
+	MODE_JOINTLY            = "jointly"
+)
+
+const (
+	INSTRUCTION_PENDING  = "pending"
+	INSTRUCTION_EXECUTED = "executed"
+	INSTRUCTION_REJECTED = "rejected"
+	INSTRUCTION_EXPIRED  = "expired"
+)
+
+const INSTRUCTION_VALIDITY = 48 * time.Hour
+
+// PendingInstruction is a debit on a jointly operated account waiting for
+// the other holders to approve it.
+type PendingInstruction struct {
+	ID                    uint
+	AccountID             uint     `pg:"on_delete:CASCADE"`
+	Account               *Account `pg:"rel:has-one"`
+	TypeOfTransaction     string
+	ModeOfPayment         string
+	ReceiverAccountNumber uuid.UUID `pg:"type:uuid"`
+	Amount                float64
+	InitiatedBy           uint
+	Status                string
+	Expiry                time.Time
+	TransactionID         uint
+	CreatedAt             time.Time
+	Approvals             []*InstructionApproval `pg:"rel:has-many"`
+}
+
+type InstructionApproval struct {
+	ID                   uint
+	PendingInstructionID uint `pg:"on_delete:CASCADE,unique:instruction_customer"`
+	CustomerID           uint `pg:"on_delete:CASCADE,unique:instruction_customer"`
+	Time                 time.Time
+}
+
+// accountHolderIDs returns the living customers holding the account, first
+// holder first, so that survivors take over when a holder dies.
+func accountHolderIDs(db orm.DB, accountID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Model((*CustomerToAccount)(nil)).
+		Column("customer_id").
+		Where("account_id = ?", accountID).
+		Where(holderRoles).
+		Where("customer_id IN (SELECT id FROM customers WHERE deceased_on IS NULL)").
+		Order("id").
+		Select(&ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOperator checks that the customer may operate the account: they
+// hold it, or they are the guardian of the minor who does. Cards and
+// cheque books are issued to such a customer, whose debits they then are.
+func checkOperator(db orm.DB, accountID uint, customerID uint) error {
+	guardianID, err := accountGuardianID(db, accountID)
+	if err != nil {
+		return err
+	}
+	if guardianID != 0 {
+		if customerID != guardianID {
+			return errors.New("only the guardian can operate a minor's account")
+		}
+		return nil
+	}
+
+	holders, err := accountHolderIDs(db, accountID)
+	if err != nil {
+		return err
+	}
+	if !containsID(holders, customerID) {
+		return errors.New("customer does not hold this account")
+	}
+	return nil
+}
+
+// SetOperatingMode changes how the holders of an account may operate it.
+// The threshold only applies to MODE_JOINTLY.
+func SetOperatingMode(accountID uint, mode string, threshold float64, actor string) (*Account, error) {
+	if threshold < 0 {
+		return nil, errors.New("joint approval threshold cannot be negative")
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if account.Status == ACCOUNT_CLOSED {
+		tx.Rollback()
+		return nil, errors.New("account is closed")
+	}
+
+	previous := account.OperatingMode
+	account.OperatingMode = mode
+	account.JointApprovalThreshold = threshold
+	_, updateErr := tx.Model(account).
+		Column("operating_mode", "joint_approval_threshold").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+
+	auditErr := RecordAudit(tx, "account", account.ID, "operating_mode_change", actor, map[string]interface{}{
+		"from":      previous,
+		"to":        mode,
+		"threshold": threshold,
+	})
+	if auditErr != nil {
+		tx.Rollback()
+		return nil, auditErr
+	}
+
+	tx.Commit()
+	return account, nil
+}
+
+// ErrJointApprovalRequired is returned for a debit above the approval
+// threshold of a jointly operated account, which every holder must approve.
+var ErrJointApprovalRequired = errors.New("debits above the joint approval threshold need the approval of every holder")
+
+// checkOperatingMode checks that initiatedBy may debit amount from the
+// account under its operating mode. Card and cheque debits are made by the
+// customer the card or cheque book was issued to, and collect payments by
+// the holder who approved them. An initiatedBy of 0 stands for a debit that
+// does not identify the customer; it is allowed only where any one holder
+// could have made it alone. A minor's account is operated by its guardian
+// alone, within the limits for minors.
+func checkOperatingMode(db orm.DB, account *Account, initiatedBy uint, amount float64) error {
+	guardianID, err := accountGuardianID(db, account.ID)
+	if err != nil {
+		return err
+	}
+	if guardianID != 0 {
+		return checkMinorDebit(db, guardianID, account.ID, initiatedBy, amount)
+	}
+
+	holders, err := accountHolderIDs(db, account.ID)
+	if err != nil {
+		return err
+	}
+
+	if initiatedBy != 0 && !containsID(holders, initiatedBy) {
+		return errors.New("initiator does not hold this account")
+	}
+
+	if len(holders) < 2 {
+		return nil
+	}
+
+	switch account.OperatingMode {
+	case MODE_FORMER_OR_SURVIVOR:
+		if initiatedBy != holders[0] {
+			return errors.New("only the first holder can operate this account")
+		}
+	case MODE_JOINTLY:
+		if amount > account.JointApprovalThreshold {
+			return ErrJointApprovalRequired
+		}
+	}
+
+	return nil
+}
+
+// ApplyOperatingMode checks that the initiator of a withdrawal or transfer
+// may operate the account. A minor's account is operated by its guardian
+// alone. When the account is operated jointly and the amount is above its
+// threshold, the debit is parked as a pending instruction which is returned
+// instead of being executed.
+func ApplyOperatingMode(transaction *Transaction) (*PendingInstruction, error) {
+	account, err := FindAccountByID(transaction.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.InitiatedBy == 0 {
+		holders, err := accountHolderIDs(database.Db, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(holders) > 1 {
+			return nil, errors.New("the initiating holder is required for joint accounts")
+		}
+	}
+
+	err = checkOperatingMode(database.Db, account, transaction.InitiatedBy, transaction.Amount)
+	if err == ErrJointApprovalRequired {
+		return createPendingInstruction(transaction)
+	}
+
+	return nil, err
+}
+
+func createPendingInstruction(transaction *Transaction) (*PendingInstruction, error) {
+	now := time.Now()
+	instruction := PendingInstruction{
+		AccountID:             transaction.AccountID,
+		TypeOfTransaction:     transaction.TypeOfTransaction,
+		ModeOfPayment:         transaction.ModeOfPayment,
+		ReceiverAccountNumber: transaction.ReceiverAccountNumber,
+		Amount:                transaction.Amount,
+		InitiatedBy:           transaction.InitiatedBy,
+		Status:                INSTRUCTION_PENDING,
+		Expiry:                now.Add(INSTRUCTION_VALIDITY),
+		CreatedAt:             now,
+	}
+
+	_, insertErr := database.Db.Model(&instruction).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &instruction, nil
+}
+
+func FindAllPendingInstructionsByAccountNumber(accNumber uuid.UUID) ([]PendingInstruction, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var instructions []PendingInstruction
+	getErr := database.Db.Model(&instructions).
+		Relation("Approvals").
+		Where("pending_instruction.account_id = ?", account.ID).
+		Order("pending_instruction.id DESC").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return instructions, nil
+}
+
+// lockInstructionForHolder loads a pending instruction for update and checks
+// that the customer is a holder other than the initiator.
+func lockInstructionForHolder(tx *pg.Tx, id uint, customerID uint) (*PendingInstruction, []uint, error) {
+	var instruction PendingInstruction
+	getErr := tx.Model(&instruction).
+		Where("id = ?", id).
+		For("UPDATE").
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, nil, errors.New("instruction does not exist")
+	}
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	if instruction.Status != INSTRUCTION_PENDING {
+		return nil, nil, fmt.Errorf("instruction is already %s", instruction.Status)
+	}
+	if !instruction.Expiry.After(time.Now()) {
+		return nil, nil, errors.New("instruction has expired")
+	}
+
+	holders, err := accountHolderIDs(tx, instruction.AccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !containsID(holders, customerID) {
+		return nil, nil, errors.New("customer does not hold this account")
+	}
+	if customerID == instruction.InitiatedBy {
+		return nil, nil, errors.New("the initiator cannot approve their own instruction")
+	}
+
+	return &instruction, holders, nil
+}
+
+// ApproveInstruction records a holder's approval and executes the debit once
+// every other holder has approved it.
+func ApproveInstruction(id uint, customerID uint) (*PendingInstruction, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	instruction, err := approveInstruction(tx, id, customerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return instruction, nil
+}
+
+func approveInstruction(tx *pg.Tx, id uint, customerID uint) (*PendingInstruction, error) {
+	instruction, holders, err := lockInstructionForHolder(tx, id, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	approval := InstructionApproval{
+		PendingInstructionID: instruction.ID,
+		CustomerID:           customerID,
+		Time:                 time.Now(),
+	}
+	_, insertErr := tx.Model(&approval).
+		OnConflict("DO NOTHING").
+		Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	approvals, err := tx.Model((*InstructionApproval)(nil)).
+		Where("pending_instruction_id = ?", instruction.ID).
+		Where("customer_id IN (?)", pg.In(holders)).
+		Count()
+	if err != nil {
+		return nil, err
+	}
+
+	if approvals >= len(holders)-1 {
+		if err := executeInstruction(tx, instruction); err != nil {
+			return nil, err
+		}
+	}
+
+	getErr := tx.Model(instruction).
+		Relation("Approvals").
+		WherePK().
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return instruction, nil
+}
+
+// executeInstruction makes a debit every holder has approved, so the
+// operating mode is not applied again.
+func executeInstruction(tx *pg.Tx, instruction *PendingInstruction) error {
+	account, err := lockAccount(tx, "id = ?", instruction.AccountID)
+	if err != nil {
+		return err
+	}
+	if err := account.CanDebit(); err != nil {
+		return err
+	}
+	if err := debitAccount(tx, account, instruction.Amount); err != nil {
+		return err
+	}
+
+	if instruction.TypeOfTransaction == TRANSACTION_TRANSFER {
+		receiver, err := lockAccount(tx, "account_number = ?", instruction.ReceiverAccountNumber)
+		if err != nil {
+			return err
+		}
+
+		if err := accountCredit(tx, receiver, instruction.Amount); err != nil {
+			return err
+		}
+	}
+
+	transaction := Transaction{
+		AccountID:             instruction.AccountID,
+		ReceiverAccountNumber: instruction.ReceiverAccountNumber,
+		ModeOfPayment:         instruction.ModeOfPayment,
+		TypeOfTransaction:     instruction.TypeOfTransaction,
+		Amount:                instruction.Amount,
+		InitiatedBy:           instruction.InitiatedBy,
+		Reference:             fmt.Sprintf("INSTRUCTION-%d", instruction.ID),
+	}
+	if err := recordTransaction(tx, &transaction); err != nil {
+		return err
+	}
+
+	instruction.Status = INSTRUCTION_EXECUTED
+	instruction.TransactionID = transaction.ID
+	_, updateErr := tx.Model(instruction).
+		Column("status", "transaction_id").
+		WherePK().
+		Update()
+
+	return updateErr
+}
+
+// RejectInstruction lets any other holder refuse a pending instruction.
+func RejectInstruction(id uint, customerID uint) (*PendingInstruction, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	instruction, _, err := lockInstructionForHolder(tx, id, customerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	instruction.Status = INSTRUCTION_REJECTED
+	_, updateErr := tx.Model(instruction).
+		Column("status").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		tx.Rollback()
+		return nil, updateErr
+	}
+
+	tx.Commit()
+	return instruction, nil
+}
+
+// ExpirePendingInstructions times out instructions that were not approved in time.
+func ExpirePendingInstructions() error {
+	return expirePendingInstructions(0)
+}
+
+func expirePendingInstructions(bankID uint) error {
+	query := database.Db.Model((*PendingInstruction)(nil)).
+		Set("status = ?", INSTRUCTION_EXPIRED).
+		Where("status = ?", INSTRUCTION_PENDING).
+		Where("expiry <= now()")
+
+	_, updateErr := inBank(query, "account_id", bankID).Update()
+	return updateErr
+}
+
+// checkNoPendingInstructions blocks the closure of an account with debits
+// still waiting for the approval of its holders.
+func checkNoPendingInstructions(tx *pg.Tx, account *Account) error {
+	count, err := tx.Model((*PendingInstruction)(nil)).
+		Where("account_id = ?", account.ID).
+		Where("status = ?", INSTRUCTION_PENDING).
+		Where("expiry > now()").
+		Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("account has instructions awaiting approval by its holders")
+	}
+	return nil
+}
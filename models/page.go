@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-pg/pg/v10/orm"
+)
+
+const (
+	PAGE_DEFAULT_LIMIT = 50
+	PAGE_MAX_LIMIT     = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListOptions selects one page of a list. Pages are keyed on the sort
+// column and the row id, so rows inserted while a client is paging do not
+// shift or repeat the rows it has already seen.
+type ListOptions struct {
+	Limit  int
+	Cursor string
+	Sort   string
+	Desc   bool
+}
+
+// Page is returned with every list. NextCursor is empty on the last page.
+type Page struct {
+	Limit      int
+	NextCursor string
+}
+
+type cursor struct {
+	Sort  string      `json:"s"`
+	Desc  bool        `json:"d"`
+	Value interface{} `json:"v"`
+	ID    uint        `json:"i"`
+}
+
+// sortKeys maps the sort keys a list accepts to the expressions it orders
+// by. Columns that may be NULL are coalesced to the zero value of their
+// field so that the row comparison of the cursor always holds.
+type sortKeys map[string]string
+
+// paginate applies the sort, cursor and limit to query. One row more than
+// the limit is selected so that page can tell whether another page follows.
+func (opts *ListOptions) paginate(query *orm.Query, table string, keys sortKeys) (*orm.Query, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = PAGE_DEFAULT_LIMIT
+	}
+	if opts.Limit > PAGE_MAX_LIMIT {
+		opts.Limit = PAGE_MAX_LIMIT
+	}
+	if opts.Sort == "" {
+		opts.Sort = "id"
+	}
+
+	expression, ok := keys[opts.Sort]
+	if !ok {
+		return nil, fmt.Errorf("cannot sort by %q", opts.Sort)
+	}
+	id := table + ".id"
+
+	direction, comparison := "ASC", ">"
+	if opts.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if opts.Cursor != "" {
+		raw, decodeErr := base64.RawURLEncoding.DecodeString(opts.Cursor)
+		if decodeErr != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		var position cursor
+		if json.Unmarshal(raw, &position) != nil || position.Sort != opts.Sort || position.Desc != opts.Desc {
+			return nil, ErrInvalidCursor
+		}
+
+		if expression == id {
+			query = query.Where(id+" "+comparison+" ?", position.ID)
+		} else {
+			query = query.Where("("+expression+", "+id+") "+comparison+" (?, ?)", position.Value, position.ID)
+		}
+	}
+
+	if expression != id {
+		query = query.OrderExpr(expression + " " + direction)
+	}
+	return query.OrderExpr(id + " " + direction).Limit(opts.Limit + 1), nil
+}
+
+// page builds the envelope of a list selected with paginate and returns the
+// number of rows that belong to the page. last gives the sort value and id
+// of the row at index i.
+func (opts *ListOptions) page(count int, last func(i int) (interface{}, uint)) (*Page, int) {
+	page := Page{Limit: opts.Limit}
+	if count <= opts.Limit {
+		return &page, count
+	}
+
+	value, id := last(opts.Limit - 1)
+	raw, _ := json.Marshal(cursor{Sort: opts.Sort, Desc: opts.Desc, Value: value, ID: id})
+	page.NextCursor = base64.RawURLEncoding.EncodeToString(raw)
+
+	return &page, opts.Limit
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// panPattern is the structure of a permanent account number: three letters,
+// the holder type, the initial of the holder's surname or name, four digits
+// and an alphabetic check character.
+var panPattern = regexp.MustCompile(`^[A-Z]{3}[ABCFGHJLPT][A-Z][0-9]{4}[A-Z]$`)
+
+// PAN_INDIVIDUAL is the holder type of a person's PAN.
+const PAN_INDIVIDUAL = 'P'
+
+func NormalizePAN(pan string) string {
+	return strings.ToUpper(strings.TrimSpace(pan))
+}
+
+// validPAN reports whether pan is a well-formed permanent account number.
+func validPAN(pan string) bool {
+	return panPattern.MatchString(NormalizePAN(pan))
+}
+
+// ValidatePAN checks that pan is well formed for a customer called name.
+// A person's PAN carries the initial of their surname in its fifth
+// character; as names are not always written surname last, any word of the
+// name is accepted.
+func ValidatePAN(pan string, name string) error {
+	pan = NormalizePAN(pan)
+	if !panPattern.MatchString(pan) {
+		return errors.New("PAN must be five letters, four digits and a letter, with a valid holder type in the fourth place")
+	}
+	if pan[3] != PAN_INDIVIDUAL {
+		return errors.New("PAN must belong to an individual")
+	}
+
+	for _, word := range strings.Fields(strings.ToUpper(name)) {
+		if word[0] == pan[4] {
+			return nil
+		}
+	}
+	return errors.New("fifth character of the PAN does not match the customer's name")
+}
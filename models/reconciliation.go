@@ -0,0 +1,373 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	DISCREPANCY_OPEN             = "open"
+	DISCREPANCY_REPAIR_REQUESTED = "repair_requested"
+	DISCREPANCY_REPAIRED         = "repaired"
+)
+
+// BalanceSnapshot is the last point at which an account's balance was found
+// to agree with its transactions: the balance after TransactionID. Accounts
+// without one are replayed from their opening balance.
+type BalanceSnapshot struct {
+	ID            uint
+	AccountID     uint    `pg:"on_delete:CASCADE,unique"`
+	Balance       float64 `pg:",use_zero"`
+	TransactionID uint    `pg:",use_zero"`
+	TakenAt       time.Time
+}
+
+type ReconciliationRun struct {
+	ID               uint
+	Actor            string
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	Accounts         int                   `pg:",use_zero"`
+	DiscrepancyCount int                   `pg:",use_zero"`
+	Discrepancies    []*BalanceDiscrepancy `pg:"rel:has-many"`
+}
+
+// BalanceDiscrepancy is an account whose balance does not match the replay
+// of its transactions. Difference is the balance less the replayed amount.
+// Balances are not kept per transaction, so FirstTransactionID is the
+// earliest transaction since the account last reconciled, or 0 if the
+// balance changed without any transaction being recorded.
+type BalanceDiscrepancy struct {
+	ID                      uint
+	ReconciliationRunID     uint      `pg:"on_delete:CASCADE"`
+	AccountID               uint      `pg:"on_delete:CASCADE"`
+	AccountNumber           uuid.UUID `pg:"type:uuid"`
+	Expected                float64   `pg:",use_zero"`
+	Actual                  float64   `pg:",use_zero"`
+	Difference              float64   `pg:",use_zero"`
+	FirstTransactionID      uint
+	Status                  string
+	RequestedBy             string
+	RequestNote             string
+	RequestedAt             time.Time
+	ApprovedBy              string
+	ApprovedAt              time.Time
+	AdjustmentTransactionID uint
+	CreatedAt               time.Time
+}
+
+// replayBalance works out what the balance of the locked account should be
+// from its snapshot and the transactions recorded since, which it returns
+// in the order they were recorded.
+func replayBalance(tx *pg.Tx, account *Account) (float64, []Transaction, error) {
+	var snapshot BalanceSnapshot
+	getErr := tx.Model(&snapshot).Where("account_id = ?", account.ID).Select()
+	if getErr == pg.ErrNoRows {
+		snapshot = BalanceSnapshot{AccountID: account.ID, Balance: account.OpeningBalance}
+	} else if getErr != nil {
+		return 0, nil, getErr
+	}
+
+	var transactions []Transaction
+	getErr = tx.Model(&transactions).
+		Column("transaction.id", "transaction.account_id", "transaction.type_of_transaction", "transaction.amount").
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("transaction.account_id = ?", account.ID).
+				WhereOr("transaction.receiver_account_number = ?", account.AccountNumber), nil
+		}).
+		Where("transaction.id > ?", snapshot.TransactionID).
+		Order("transaction.id").
+		Select()
+	if getErr != nil {
+		return 0, nil, getErr
+	}
+
+	expected := snapshot.Balance
+	for i := range transactions {
+		expected += transactions[i].signedAmount(account.ID)
+	}
+
+	return roundAmount(expected), transactions, nil
+}
+
+func saveBalanceSnapshot(tx *pg.Tx, accountID uint, balance float64, transactionID uint) error {
+	_, insertErr := tx.Model(&BalanceSnapshot{
+		AccountID:     accountID,
+		Balance:       balance,
+		TransactionID: transactionID,
+		TakenAt:       time.Now(),
+	}).
+		OnConflict("(account_id) DO UPDATE").
+		Set("balance = EXCLUDED.balance").
+		Set("transaction_id = EXCLUDED.transaction_id").
+		Set("taken_at = EXCLUDED.taken_at").
+		Insert()
+	return insertErr
+}
+
+// reconcileAccount compares the balance of an account with the replay of its
+// transactions. A matching account has its snapshot moved up to its latest
+// transaction; otherwise the discrepancy is returned for the run to record.
+func reconcileAccount(tx *pg.Tx, accountID uint) (*BalanceDiscrepancy, error) {
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, transactions, err := replayBalance(tx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	difference := roundAmount(account.Balance - expected)
+	if difference == 0 {
+		if len(transactions) == 0 {
+			return nil, nil
+		}
+		return nil, saveBalanceSnapshot(tx, account.ID, account.Balance, transactions[len(transactions)-1].ID)
+	}
+
+	discrepancy := BalanceDiscrepancy{
+		AccountID:     account.ID,
+		AccountNumber: account.AccountNumber,
+		Expected:      expected,
+		Actual:        account.Balance,
+		Difference:    difference,
+		Status:        DISCREPANCY_OPEN,
+	}
+	if len(transactions) > 0 {
+		discrepancy.FirstTransactionID = transactions[0].ID
+	}
+
+	return &discrepancy, nil
+}
+
+// ReconcileBalances replays the transactions of every account and reports
+// those whose balance has drifted from them. Each account is locked only
+// while it is checked, so postings carry on during the run.
+func ReconcileBalances(actor string) (*ReconciliationRun, error) {
+	run := ReconciliationRun{Actor: actor, StartedAt: time.Now()}
+	_, insertErr := database.Db.Model(&run).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	var accountIDs []uint
+	getErr := database.Db.Model((*Account)(nil)).Column("id").Order("id").Select(&accountIDs)
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	for _, accountID := range accountIDs {
+		tx, txErr := database.Db.Begin()
+		if txErr != nil {
+			return nil, txErr
+		}
+
+		discrepancy, err := reconcileAccount(tx, accountID)
+		if err == nil && discrepancy != nil {
+			discrepancy.ReconciliationRunID = run.ID
+			discrepancy.CreatedAt = time.Now()
+			_, err = tx.Model(discrepancy).Returning("*").Insert()
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("account %d: %w", accountID, err)
+		}
+
+		tx.Commit()
+		run.Accounts++
+		if discrepancy != nil {
+			run.Discrepancies = append(run.Discrepancies, discrepancy)
+		}
+	}
+
+	run.DiscrepancyCount = len(run.Discrepancies)
+	run.FinishedAt = time.Now()
+	_, updateErr := database.Db.Model(&run).
+		Column("accounts", "discrepancy_count", "finished_at").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	return &run, nil
+}
+
+// ReconcileAllBalances is the scheduled reconciliation run.
+func ReconcileAllBalances() error {
+	_, err := ReconcileBalances("system")
+	return err
+}
+
+func FindReconciliationRunByID(id uint) (*ReconciliationRun, error) {
+	var run ReconciliationRun
+	getErr := database.Db.Model(&run).
+		Relation("Discrepancies", func(q *pg.Query) (*pg.Query, error) {
+			return q.Order("balance_discrepancy.id"), nil
+		}).
+		Where("reconciliation_run.id = ?", id).
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("reconciliation run does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &run, nil
+}
+
+func FindAllReconciliationRuns() ([]ReconciliationRun, error) {
+	var runs []ReconciliationRun
+	getErr := database.Db.Model(&runs).Order("id DESC").Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return runs, nil
+}
+
+// RequestBalanceRepair asks for an adjustment entry to bring an account
+// back in line with its transactions. A supervisor must approve it.
+func RequestBalanceRepair(id uint, note string, actor string) (*BalanceDiscrepancy, error) {
+	discrepancy := BalanceDiscrepancy{
+		ID:          id,
+		Status:      DISCREPANCY_REPAIR_REQUESTED,
+		RequestedBy: actor,
+		RequestNote: note,
+		RequestedAt: time.Now(),
+	}
+	result, updateErr := database.Db.Model(&discrepancy).
+		Column("status", "requested_by", "request_note", "requested_at").
+		WherePK().
+		Where("status = ?", DISCREPANCY_OPEN).
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errors.New("discrepancy does not exist or is not open")
+	}
+
+	return &discrepancy, nil
+}
+
+// ApproveBalanceRepair posts the adjustment entry of a requested repair,
+// setting the balance to the replayed amount. supervisorID must be an
+// active supervisor of the account's bank other than the requester. The
+// account is checked again first and the repair refused if the difference
+// is no longer the one found.
+func ApproveBalanceRepair(id uint, supervisorID uint) (*BalanceDiscrepancy, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	discrepancy, err := approveBalanceRepair(tx, id, supervisorID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return discrepancy, nil
+}
+
+func approveBalanceRepair(tx *pg.Tx, id uint, supervisorID uint) (*BalanceDiscrepancy, error) {
+	var discrepancy BalanceDiscrepancy
+	getErr := tx.Model(&discrepancy).Where("id = ?", id).For("UPDATE").Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("discrepancy does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if discrepancy.Status != DISCREPANCY_REPAIR_REQUESTED {
+		return nil, fmt.Errorf("discrepancy is %s, not awaiting approval", discrepancy.Status)
+	}
+
+	supervisor, err := findSupervisor(tx, supervisorID, discrepancy.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	actor := supervisor.actor()
+	if actor == discrepancy.RequestedBy {
+		return nil, errors.New("the requester cannot approve their own repair")
+	}
+
+	account, err := lockAccount(tx, "id = ?", discrepancy.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, _, err := replayBalance(tx, account)
+	if err != nil {
+		return nil, err
+	}
+	if roundAmount(account.Balance-expected) != discrepancy.Difference {
+		return nil, errors.New("the account has changed since the discrepancy was found; reconcile it again")
+	}
+
+	adjustment := Transaction{
+		AccountID:         account.ID,
+		ModeOfPayment:     "Internal",
+		TypeOfTransaction: TRANSACTION_ADJUSTMENT_CREDIT,
+		Amount:            math.Abs(discrepancy.Difference),
+		Reference:         fmt.Sprintf("RECON-%d", discrepancy.ID),
+	}
+	if discrepancy.Difference > 0 {
+		adjustment.TypeOfTransaction = TRANSACTION_ADJUSTMENT_DEBIT
+	}
+
+	_, updateErr := tx.Model(account).
+		Set("balance = ?", expected).
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	if err := recordTransaction(tx, &adjustment); err != nil {
+		return nil, err
+	}
+
+	// The adjustment accounts for the drift, so the account replays from
+	// after it rather than counting it a second time.
+	if err := saveBalanceSnapshot(tx, account.ID, expected, adjustment.ID); err != nil {
+		return nil, err
+	}
+
+	discrepancy.Status = DISCREPANCY_REPAIRED
+	discrepancy.ApprovedBy = actor
+	discrepancy.ApprovedAt = time.Now()
+	discrepancy.AdjustmentTransactionID = adjustment.ID
+	_, updateErr = tx.Model(&discrepancy).
+		Column("status", "approved_by", "approved_at", "adjustment_transaction_id").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	auditErr := RecordAudit(tx, "account", account.ID, "balance_adjustment", actor, map[string]interface{}{
+		"discrepancy_id": discrepancy.ID,
+		"from":           account.Balance,
+		"to":             expected,
+		"requested_by":   discrepancy.RequestedBy,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return &discrepancy, nil
+}
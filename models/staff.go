@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	STAFF_OFFICER    = "officer"
+	STAFF_SUPERVISOR = "supervisor"
+)
+
+// Staff is an employee of a branch. Only supervisors may approve postings
+// that need an override.
+type Staff struct {
+	ID       uint
+	BranchID uint    `pg:"on_delete:RESTRICT"`
+	Branch   *Branch `pg:"rel:has-one"`
+	Name     string
+	Role     string
+	Active   bool `pg:",use_zero"`
+}
+
+func (staff *Staff) Save() (*Staff, error) {
+	if staff.Role != STAFF_OFFICER && staff.Role != STAFF_SUPERVISOR {
+		return nil, errors.New("staff role must be officer or supervisor")
+	}
+
+	if _, err := FindBranchByID(staff.BranchID); err != nil {
+		return nil, errors.New("branch does not exist")
+	}
+
+	staff.Active = true
+	_, insertErr := database.Db.Model(staff).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return staff, nil
+}
+
+func FindStaffByID(id uint) (*Staff, error) {
+	var output Staff
+	getErr := database.Db.Model(&output).
+		Where("id = ?", id).
+		Select()
+
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("staff member does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &output, nil
+}
+
+// DeactivateStaff stops a staff member from approving anything further.
+func DeactivateStaff(id uint) (*Staff, error) {
+	staff, err := FindStaffByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	staff.Active = false
+	_, updateErr := database.Db.Model(staff).
+		Column("active").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	return staff, nil
+}
+
+// actor names the staff member in audit records and approvals.
+func (staff *Staff) actor() string {
+	return fmt.Sprintf("%s (staff %d)", staff.Name, staff.ID)
+}
+
+// findSupervisor returns the staff member with the given ID if they are an
+// active supervisor at a branch of the bank the account belongs to.
+func findSupervisor(db orm.DB, staffID uint, accountID uint) (*Staff, error) {
+	var staff Staff
+	getErr := db.Model(&staff).
+		Where("id = ?", staffID).
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("staff member does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if !staff.Active || staff.Role != STAFF_SUPERVISOR {
+		return nil, fmt.Errorf("staff member %d is not an active supervisor", staff.ID)
+	}
+
+	count, err := db.Model((*Account)(nil)).
+		Join("JOIN branches b ON b.id = account.branch_id").
+		Join("JOIN branches s ON s.bank_id = b.bank_id").
+		Where("account.id = ?", accountID).
+		Where("s.id = ?", staff.BranchID).
+		Count()
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("staff member %d does not work for the bank of the account", staff.ID)
+	}
+
+	return &staff, nil
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+// creditTypes are the transaction types that add money to the account they
+// are recorded against. Every other type takes money out of it, and
+// transfers are credits to the account they are received into.
+var creditTypes = map[string]bool{
+	TRANSACTION_DEPOSIT:  true,
+	TRANSACTION_INTEREST: true,
+	TRANSACTION_REVERSAL: true,
+
+	TRANSACTION_ADJUSTMENT_CREDIT: true,
+}
+
+type StatementLine struct {
+	TransactionID uint
+	Time          time.Time
+	Description   string
+	Reference     string
+	Debit         float64
+	Credit        float64
+	Balance       float64
+}
+
+type Statement struct {
+	BankName       string
+	BranchAddress  string
+	IFSC           string
+	AccountNumber  string
+	AccountType    string
+	Holders        []string
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	TotalDebits    float64
+	TotalCredits   float64
+	ClosingBalance float64
+	Lines          []StatementLine
+}
+
+// MaskAccountNumber hides all but the last four characters of an account number.
+func MaskAccountNumber(number uuid.UUID) string {
+	text := number.String()
+	masked := []byte(text)
+	for i := 0; i < len(masked)-4; i++ {
+		if masked[i] != '-' {
+			masked[i] = 'X'
+		}
+	}
+	return string(masked)
+}
+
+// signedAmount is the effect of the transaction on the account's balance.
+func (transaction *Transaction) signedAmount(accountID uint) float64 {
+	if transaction.AccountID == accountID && !creditTypes[transaction.TypeOfTransaction] {
+		return -transaction.Amount
+	}
+	return transaction.Amount
+}
+
+func (transaction *Transaction) describe(accountID uint) string {
+	description := transaction.TypeOfTransaction
+	if transaction.ModeOfPayment != "" {
+		description += " / " + transaction.ModeOfPayment
+	}
+
+	if transaction.AccountID != accountID && transaction.Account != nil {
+		description += " from " + MaskAccountNumber(transaction.Account.AccountNumber)
+	} else if transaction.ReceiverAccountNumber != uuid.Nil {
+		description += " to " + MaskAccountNumber(transaction.ReceiverAccountNumber)
+	}
+
+	return description
+}
+
+// GenerateStatement builds the statement of an account for the days from
+// and to, both inclusive. The opening balance is worked back from the
+// current balance so that balances carried in when the account was opened
+// are accounted for.
+func GenerateStatement(accNumber uuid.UUID, from time.Time, to time.Time) (*Statement, error) {
+	if to.Before(from) {
+		return nil, errors.New("statement period ends before it starts")
+	}
+	end := to.AddDate(0, 0, 1)
+
+	var account Account
+	getErr := database.Db.Model(&account).
+		Relation("Branch.Bank").
+		Where("account.account_number = ?", accNumber).
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("account does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	var transactions []Transaction
+	getErr = database.Db.Model(&transactions).
+		Relation("Account").
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("transaction.account_id = ?", account.ID).
+				WhereOr("transaction.receiver_account_number = ?", account.AccountNumber), nil
+		}).
+		Where("transaction.time >= ?", from).
+		Order("transaction.time", "transaction.id").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	statement := Statement{
+		AccountNumber: MaskAccountNumber(account.AccountNumber),
+		AccountType:   account.AccountType,
+		From:          from,
+		To:            to,
+	}
+	if account.Branch != nil {
+		statement.BranchAddress = account.Branch.Address
+		statement.IFSC = strings.ToUpper(account.Branch.IFSC_CODE.String())
+		if account.Branch.Bank != nil {
+			statement.BankName = account.Branch.Bank.Name
+		}
+	}
+
+	getErr = database.Db.Model((*Customer)(nil)).
+		Column("name").
+		Where("id IN (SELECT customer_id FROM customer_to_accounts WHERE account_id = ? AND "+holderRoles+")", account.ID).
+		Order("id").
+		Select(&statement.Holders)
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	since := 0.0
+	for i := range transactions {
+		since += transactions[i].signedAmount(account.ID)
+	}
+	statement.OpeningBalance = roundAmount(account.Balance - since)
+
+	balance := statement.OpeningBalance
+	for i := range transactions {
+		transaction := &transactions[i]
+		if !transaction.Time.Before(end) {
+			break
+		}
+
+		amount := transaction.signedAmount(account.ID)
+		balance += amount
+
+		line := StatementLine{
+			TransactionID: transaction.ID,
+			Time:          transaction.Time,
+			Description:   transaction.describe(account.ID),
+			Reference:     transaction.Reference,
+			Balance:       roundAmount(balance),
+		}
+		if amount < 0 {
+			line.Debit = -amount
+			statement.TotalDebits += -amount
+		} else {
+			line.Credit = amount
+			statement.TotalCredits += amount
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	statement.TotalDebits = roundAmount(statement.TotalDebits)
+	statement.TotalCredits = roundAmount(statement.TotalCredits)
+	statement.ClosingBalance = roundAmount(balance)
+
+	return &statement, nil
+}
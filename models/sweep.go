@@ -0,0 +1,402 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const ACCOUNT_TYPE_SWEEP_DEPOSIT = "Sweep Deposit"
+
+const TRANSACTION_SWEEP = "Sweep"
+
+// SweepRule moves the balance of an account above Threshold into its linked
+// deposit account in multiples of Unit, and breaks units of the deposit back
+// when a debit would otherwise fail.
+type SweepRule struct {
+	ID               uint
+	AccountID        uint     `pg:"on_delete:CASCADE,unique"`
+	Account          *Account `pg:"rel:has-one"`
+	DepositAccountID uint     `pg:"on_delete:CASCADE"`
+	DepositAccount   *Account `pg:"rel:has-one"`
+	Threshold        float64  `pg:",use_zero"`
+	Unit             float64
+	Active           bool `pg:",use_zero"`
+	CreatedAt        time.Time
+}
+
+// SaveSweepRule creates or updates the sweep rule of an account. The linked
+// deposit account is opened in the same branch for the same holders the
+// first time a rule is saved.
+func SaveSweepRule(accountID uint, threshold float64, unit float64, actor string) (*SweepRule, error) {
+	if threshold < MIN_BALANCE {
+		return nil, fmt.Errorf("threshold cannot be below the minimum balance of %v", MIN_BALANCE)
+	}
+	if unit <= 0 {
+		return nil, errors.New("unit must be positive")
+	}
+
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	rule, err := saveSweepRule(tx, accountID, threshold, unit, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return rule, nil
+}
+
+func saveSweepRule(tx *pg.Tx, accountID uint, threshold float64, unit float64, actor string) (*SweepRule, error) {
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Status == ACCOUNT_CLOSED {
+		return nil, errors.New("account is closed")
+	}
+	if account.AccountType == ACCOUNT_TYPE_SWEEP_DEPOSIT {
+		return nil, errors.New("a sweep deposit cannot have its own sweep rule")
+	}
+
+	var rule SweepRule
+	getErr := tx.Model(&rule).Where("account_id = ?", account.ID).Select()
+
+	if getErr == pg.ErrNoRows {
+		deposit := Account{
+			BranchID:    account.BranchID,
+			AccountType: ACCOUNT_TYPE_SWEEP_DEPOSIT,
+		}
+		_, insertErr := tx.Model(&deposit).Returning("*").Insert()
+		if insertErr != nil {
+			return nil, insertErr
+		}
+
+		_, insertErr = tx.Exec(`INSERT INTO customer_to_accounts (account_id, customer_id, role)
+			SELECT ?, customer_id, role FROM customer_to_accounts WHERE account_id = ? AND `+holderRoles, deposit.ID, account.ID)
+		if insertErr != nil {
+			return nil, insertErr
+		}
+
+		rule = SweepRule{
+			AccountID:        account.ID,
+			DepositAccountID: deposit.ID,
+			CreatedAt:        time.Now(),
+		}
+	} else if getErr != nil {
+		return nil, getErr
+	}
+
+	rule.Threshold = threshold
+	rule.Unit = unit
+	rule.Active = true
+
+	if rule.ID == 0 {
+		_, insertErr := tx.Model(&rule).Returning("*").Insert()
+		if insertErr != nil {
+			return nil, insertErr
+		}
+	} else {
+		_, updateErr := tx.Model(&rule).
+			Column("threshold", "unit", "active").
+			WherePK().
+			Update()
+		if updateErr != nil {
+			return nil, updateErr
+		}
+	}
+
+	auditErr := RecordAudit(tx, "account", account.ID, "sweep_rule", actor, map[string]interface{}{
+		"threshold":          threshold,
+		"unit":               unit,
+		"deposit_account_id": rule.DepositAccountID,
+	})
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return &rule, nil
+}
+
+func FindSweepRuleByAccountID(accountID uint) (*SweepRule, error) {
+	var rule SweepRule
+	getErr := database.Db.Model(&rule).
+		Relation("Account").
+		Relation("DepositAccount").
+		Where("sweep_rule.account_id = ?", accountID).
+		Select()
+
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("account has no sweep rule")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &rule, nil
+}
+
+// moveSweepFunds moves amount between an account and its sweep deposit and
+// records it against the account it left.
+func moveSweepFunds(tx *pg.Tx, from *Account, to *Account, amount float64, reference string) error {
+	_, updateErr := tx.Model(from).
+		Set("balance = balance - ?", amount).
+		WherePK().
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return updateErr
+	}
+
+	_, updateErr = tx.Model(to).
+		Set("balance = balance + ?", amount).
+		WherePK().
+		Returning("*").
+		Update()
+	if updateErr != nil {
+		return updateErr
+	}
+
+	return recordTransaction(tx, &Transaction{
+		AccountID:             from.ID,
+		ReceiverAccountNumber: to.AccountNumber,
+		ModeOfPayment:         "Internal",
+		TypeOfTransaction:     TRANSACTION_SWEEP,
+		Amount:                amount,
+		Reference:             reference,
+	})
+}
+
+// sweepIn breaks units of the linked deposit so that a debit of amount from
+// the locked account can succeed. It does nothing when the account has no
+// active rule or the deposit cannot cover a single unit.
+func sweepIn(tx *pg.Tx, account *Account, amount float64) error {
+	var rule SweepRule
+	getErr := tx.Model(&rule).
+		Where("account_id = ?", account.ID).
+		Where("active").
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil
+	}
+	if getErr != nil {
+		return getErr
+	}
+
+	var held float64
+	err := activeHoldsTotal(tx, account.ID).Select(pg.Scan(&held))
+	if err != nil {
+		return err
+	}
+
+	shortfall := amount + held + MIN_BALANCE - account.Balance
+	if shortfall <= 0 {
+		return nil
+	}
+
+	deposit, err := lockAccount(tx, "id = ?", rule.DepositAccountID)
+	if err != nil {
+		return err
+	}
+	if deposit.CanDebit() != nil {
+		return nil
+	}
+
+	units := math.Min(math.Ceil(shortfall/rule.Unit), math.Floor(deposit.Balance/rule.Unit))
+	if units < 1 {
+		return nil
+	}
+
+	return moveSweepFunds(tx, deposit, account, units*rule.Unit, fmt.Sprintf("SWEEP-IN-%d", rule.ID))
+}
+
+// sweepOut moves whole units of the available balance above the threshold
+// into the linked deposit.
+func sweepOut(tx *pg.Tx, rule *SweepRule) (float64, error) {
+	account, err := lockAccount(tx, "id = ?", rule.AccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	deposit, err := lockAccount(tx, "id = ?", rule.DepositAccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	if account.CanDebit() != nil || deposit.CanCredit() != nil {
+		return 0, nil
+	}
+
+	var held float64
+	err = activeHoldsTotal(tx, account.ID).Select(pg.Scan(&held))
+	if err != nil {
+		return 0, err
+	}
+
+	units := math.Floor((account.Balance - held - rule.Threshold) / rule.Unit)
+	if units < 1 {
+		return 0, nil
+	}
+
+	amount := units * rule.Unit
+	err = moveSweepFunds(tx, account, deposit, amount, fmt.Sprintf("SWEEP-OUT-%d", rule.ID))
+	if err != nil {
+		return 0, err
+	}
+
+	return amount, nil
+}
+
+// RunSweep sweeps the surplus of one account into its deposit and returns
+// the amount moved.
+func RunSweep(accountID uint) (float64, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return 0, txErr
+	}
+
+	var rule SweepRule
+	getErr := tx.Model(&rule).
+		Where("account_id = ?", accountID).
+		Where("active").
+		Select()
+	if getErr == pg.ErrNoRows {
+		tx.Rollback()
+		return 0, errors.New("account has no active sweep rule")
+	}
+	if getErr != nil {
+		tx.Rollback()
+		return 0, getErr
+	}
+
+	amount, err := sweepOut(tx, &rule)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	tx.Commit()
+	return amount, nil
+}
+
+// RunAllSweeps is the end-of-day sweep-out over every active rule. A rule
+// that fails is logged and the rest still run.
+func RunAllSweeps() error {
+	var rules []SweepRule
+	getErr := database.Db.Model(&rules).Where("active").Select()
+	if getErr != nil {
+		return getErr
+	}
+
+	failed := 0
+	for _, rule := range rules {
+		if _, err := RunSweep(rule.AccountID); err != nil {
+			log.Printf("sweep rule %d of account %d failed: %s\n", rule.ID, rule.AccountID, err.Error())
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sweep rules failed", failed, len(rules))
+	}
+	return nil
+}
+
+// DisableSweepRule stops sweeping for an account and breaks the whole
+// deposit back into it.
+func DisableSweepRule(accountID uint, actor string) (*SweepRule, error) {
+	tx, txErr := database.Db.Begin()
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	rule, err := disableSweepRule(tx, accountID, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return rule, nil
+}
+
+func disableSweepRule(tx *pg.Tx, accountID uint, actor string) (*SweepRule, error) {
+	var rule SweepRule
+	getErr := tx.Model(&rule).
+		Where("account_id = ?", accountID).
+		For("UPDATE").
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("account has no sweep rule")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if !rule.Active {
+		return nil, errors.New("sweep rule is already disabled")
+	}
+
+	account, err := lockAccount(tx, "id = ?", rule.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	deposit, err := lockAccount(tx, "id = ?", rule.DepositAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deposit.Balance > 0 {
+		err = moveSweepFunds(tx, deposit, account, deposit.Balance, fmt.Sprintf("SWEEP-IN-%d", rule.ID))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rule.Active = false
+	_, updateErr := tx.Model(&rule).
+		Column("active").
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	auditErr := RecordAudit(tx, "account", account.ID, "sweep_rule_disabled", actor, nil)
+	if auditErr != nil {
+		return nil, auditErr
+	}
+
+	return &rule, nil
+}
+
+// checkNoSweepDeposit blocks closing an account whose sweep deposit still
+// holds funds, or closing the sweep deposit itself while the rule is active.
+func checkNoSweepDeposit(tx *pg.Tx, account *Account) error {
+	count, err := tx.Model((*SweepRule)(nil)).
+		Where("active").
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("account_id = ?", account.ID).
+				WhereOr("deposit_account_id = ?", account.ID), nil
+		}).
+		Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("disable the sweep rule before closing the account")
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	// TDS_THRESHOLD is the interest a customer may earn in a financial year
+	// across all their accounts before tax is withheld.
+	TDS_THRESHOLD = 40000.00
+	// TDS_RATE applies to customers with a valid PAN and TDS_RATE_NO_PAN to
+	// everyone else.
+	TDS_RATE        = 0.10
+	TDS_RATE_NO_PAN = 0.20
+)
+
+const (
+	TDS_FORM_15G = "15G"
+	TDS_FORM_15H = "15H"
+)
+
+// TDSExemption is a customer's declaration (form 15G or 15H) that their
+// income is below the taxable limit, suppressing withholding for a year.
+type TDSExemption struct {
+	ID            uint
+	CustomerID    uint   `pg:"on_delete:CASCADE,unique:customer_year"`
+	FinancialYear string `pg:",unique:customer_year"`
+	Form          string
+	RecordedBy    string
+	DeclaredAt    time.Time
+}
+
+// TDSLedgerEntry is one interest credit of a customer and the tax withheld
+// from it. Interest on joint accounts is attributed to the first holder.
+type TDSLedgerEntry struct {
+	ID                    uint
+	CustomerID            uint `pg:"on_delete:CASCADE"`
+	AccountID             uint `pg:"on_delete:CASCADE"`
+	FinancialYear         string
+	Quarter               int
+	InterestTransactionID uint
+	InterestAmount        float64
+	PANValid              bool `pg:",use_zero"`
+	Rate                  float64
+	TaxWithheld           float64 `pg:",use_zero"`
+	TDSTransactionID      uint
+	Exempt                bool `pg:",use_zero"`
+	CreatedAt             time.Time
+}
+
+// financialYear names the April to March year t falls in, such as 2026-27,
+// and returns the quarter of that year.
+func financialYear(t time.Time) (string, int) {
+	year := t.Year()
+	if t.Month() < time.April {
+		year--
+	}
+	quarter := (int(t.Month())+8)%12/3 + 1
+	return fmt.Sprintf("%d-%02d", year, (year+1)%100), quarter
+}
+
+func CurrentFinancialYear() string {
+	year, _ := financialYear(time.Now())
+	return year
+}
+
+// financialYearStart parses a year named by financialYear into its first day.
+func financialYearStart(name string) (time.Time, error) {
+	var year, next int
+	if _, err := fmt.Sscanf(name, "%4d-%2d", &year, &next); err != nil || (year+1)%100 != next {
+		return time.Time{}, errors.New("financial year must look like 2026-27")
+	}
+	return time.Date(year, time.April, 1, 0, 0, 0, 0, time.Local), nil
+}
+
+// taxPayerID is the first holder of an account, who is taxed on its
+// interest. Unlike accountHolderIDs it includes deceased holders, whose
+// accounts are still credited interest when they are settled.
+func taxPayerID(tx *pg.Tx, accountID uint) (uint, error) {
+	var customerID uint
+	err := tx.Model((*CustomerToAccount)(nil)).
+		Column("customer_id").
+		Where("account_id = ?", accountID).
+		Where(holderRoles).
+		Order("id").
+		Limit(1).
+		Select(pg.Scan(&customerID))
+	if err == pg.ErrNoRows {
+		return 0, errors.New("account has no holder to attribute interest to")
+	}
+	return customerID, err
+}
+
+// assessTax works out the tax to withhold from interest about to be
+// credited to account. Once a customer's interest for the year passes the
+// threshold, tax is due on the whole year's interest, less what has already
+// been withheld; available caps it at what the account can pay.
+func assessTax(tx *pg.Tx, account *Account, interest float64, available float64, now time.Time) (*TDSLedgerEntry, error) {
+	customerID, err := taxPayerID(tx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer Customer
+	getErr := tx.Model(&customer).Where("id = ?", customerID).Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	year, quarter := financialYear(now)
+	entry := TDSLedgerEntry{
+		CustomerID:     customerID,
+		AccountID:      account.ID,
+		FinancialYear:  year,
+		Quarter:        quarter,
+		InterestAmount: interest,
+		PANValid:       validPAN(customer.PAN),
+		Rate:           TDS_RATE_NO_PAN,
+		CreatedAt:      now,
+	}
+	if entry.PANValid {
+		entry.Rate = TDS_RATE
+	}
+
+	exempt, err := tx.Model((*TDSExemption)(nil)).
+		Where("customer_id = ?", customerID).
+		Where("financial_year = ?", year).
+		Exists()
+	if err != nil {
+		return nil, err
+	}
+	if exempt {
+		entry.Exempt = true
+		entry.Rate = 0
+		return &entry, nil
+	}
+
+	var totals struct {
+		Interest float64
+		Withheld float64
+	}
+	_, err = tx.QueryOne(&totals, `
+		SELECT COALESCE(sum(interest_amount), 0) AS interest, COALESCE(sum(tax_withheld), 0) AS withheld
+		FROM tds_ledger_entries WHERE customer_id = ? AND financial_year = ?`, customerID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	if totals.Interest+interest > TDS_THRESHOLD {
+		due := roundAmount((totals.Interest+interest)*entry.Rate - totals.Withheld)
+		entry.TaxWithheld = math.Max(0, math.Min(due, available))
+	}
+
+	return &entry, nil
+}
+
+// recordWithholding posts the tax in entry as a TDS transaction against the
+// account and adds entry to the customer's ledger. The caller takes the tax
+// off the balance. It returns the TDS transaction, or nil if no tax was due.
+func recordWithholding(tx *pg.Tx, entry *TDSLedgerEntry, credit *Transaction) (*Transaction, error) {
+	entry.InterestTransactionID = credit.ID
+
+	var withholding *Transaction
+	if entry.TaxWithheld > 0 {
+		withholding = &Transaction{
+			AccountID:         entry.AccountID,
+			ModeOfPayment:     "Internal",
+			TypeOfTransaction: TRANSACTION_TDS,
+			Amount:            entry.TaxWithheld,
+			Reference:         fmt.Sprintf("TDS-%s-Q%d", entry.FinancialYear, entry.Quarter),
+			Time:              credit.Time,
+		}
+		if err := recordTransaction(tx, withholding); err != nil {
+			return nil, err
+		}
+		entry.TDSTransactionID = withholding.ID
+	}
+
+	_, insertErr := tx.Model(entry).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return withholding, nil
+}
+
+// SaveTDSExemption records a customer's declaration for a financial year,
+// replacing any earlier one. Interest credited before it stays taxed.
+func SaveTDSExemption(customerID uint, financialYear string, form string, actor string) (*TDSExemption, error) {
+	if _, err := financialYearStart(financialYear); err != nil {
+		return nil, err
+	}
+	if form != TDS_FORM_15G && form != TDS_FORM_15H {
+		return nil, errors.New("declaration form must be 15G or 15H")
+	}
+
+	if _, err := FindCustomerByID(customerID); err != nil {
+		return nil, err
+	}
+
+	exemption := TDSExemption{
+		CustomerID:    customerID,
+		FinancialYear: financialYear,
+		Form:          form,
+		RecordedBy:    actor,
+		DeclaredAt:    time.Now(),
+	}
+	_, insertErr := database.Db.Model(&exemption).
+		OnConflict("(customer_id, financial_year) DO UPDATE").
+		Set("form = EXCLUDED.form").
+		Set("recorded_by = EXCLUDED.recorded_by").
+		Set("declared_at = EXCLUDED.declared_at").
+		Returning("*").
+		Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &exemption, nil
+}
+
+func FindAllTDSLedgerEntries(customerID uint, financialYear string) ([]TDSLedgerEntry, error) {
+	if _, err := financialYearStart(financialYear); err != nil {
+		return nil, err
+	}
+
+	var entries []TDSLedgerEntry
+	getErr := database.Db.Model(&entries).
+		Where("customer_id = ?", customerID).
+		Where("financial_year = ?", financialYear).
+		Order("id").
+		Select()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return entries, nil
+}
+
+type TDSQuarter struct {
+	Quarter      int
+	From         time.Time
+	To           time.Time
+	InterestPaid float64
+	TaxWithheld  float64
+	Credits      int
+}
+
+// TDSCertificate summarises the interest paid to a customer in a financial
+// year and the tax withheld from it, quarter by quarter.
+type TDSCertificate struct {
+	BankName      string
+	BranchAddress string
+	IFSC          string
+	CustomerID    uint
+	CustomerName  string
+	PAN           string
+	FinancialYear string
+	Exempt        bool
+	Quarters      []TDSQuarter
+	InterestPaid  float64
+	TaxWithheld   float64
+}
+
+func GenerateTDSCertificate(customerID uint, financialYear string) (*TDSCertificate, error) {
+	start, err := financialYearStart(financialYear)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer Customer
+	getErr := database.Db.Model(&customer).
+		Relation("Branch.Bank").
+		Where("customer.id = ?", customerID).
+		Select()
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("customer does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	certificate := TDSCertificate{
+		CustomerID:    customer.ID,
+		CustomerName:  customer.Name,
+		PAN:           strings.ToUpper(customer.PAN),
+		FinancialYear: financialYear,
+	}
+	if customer.Branch != nil {
+		certificate.BranchAddress = customer.Branch.Address
+		certificate.IFSC = strings.ToUpper(customer.Branch.IFSC_CODE.String())
+		if customer.Branch.Bank != nil {
+			certificate.BankName = customer.Branch.Bank.Name
+		}
+	}
+
+	certificate.Exempt, err = database.Db.Model((*TDSExemption)(nil)).
+		Where("customer_id = ?", customerID).
+		Where("financial_year = ?", financialYear).
+		Exists()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := FindAllTDSLedgerEntries(customerID, financialYear)
+	if err != nil {
+		return nil, err
+	}
+
+	for quarter := 1; quarter <= 4; quarter++ {
+		from := start.AddDate(0, 3*(quarter-1), 0)
+		certificate.Quarters = append(certificate.Quarters, TDSQuarter{
+			Quarter: quarter,
+			From:    from,
+			To:      from.AddDate(0, 3, -1),
+		})
+	}
+	for _, entry := range entries {
+		summary := &certificate.Quarters[entry.Quarter-1]
+		summary.InterestPaid = roundAmount(summary.InterestPaid + entry.InterestAmount)
+		summary.TaxWithheld = roundAmount(summary.TaxWithheld + entry.TaxWithheld)
+		summary.Credits++
+
+		certificate.InterestPaid = roundAmount(certificate.InterestPaid + entry.InterestAmount)
+		certificate.TaxWithheld = roundAmount(certificate.TaxWithheld + entry.TaxWithheld)
+	}
+
+	return &certificate, nil
+}
@@ -3,129 +3,216 @@ package models
 import (
 	"github.com/shouryagautam/bankdeploy/database"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/google/uuid"
 )
 
 const MIN_BALANCE = 2000.00
 
+const (
+	TRANSACTION_DEPOSIT  = "Deposit"
+	TRANSACTION_WITHDRAW = "Withdraw"
+	TRANSACTION_TRANSFER = "Transfer"
+	TRANSACTION_INTEREST = "Interest"
+	TRANSACTION_FEE      = "Fee"
+	TRANSACTION_TDS      = "TDS"
+
+	TRANSACTION_ADJUSTMENT_CREDIT = "AdjustmentCredit"
+	TRANSACTION_ADJUSTMENT_DEBIT  = "AdjustmentDebit"
+)
+
 type Transaction struct{
 	ID uint
 	AccountID uint `pg:"on_delete:RESTRICT"`
 	Account *Account `pg:"rel:has-one"`
 	ReceiverAccountNumber uuid.UUID `pg:"type:uuid"`
+	ReceiverVPA string
 	ModeOfPayment string
 	TypeOfTransaction string
 	Amount float64
+	Reference string
+	InitiatedBy uint
 	Time time.Time 
+	// ValueDate is the day the posting takes effect and BookingDate the
+	// business day it was booked on. OverrideBy names the supervisor who
+	// allowed a value date on a day that was already closed; such postings
+	// are made only through PostBackDated.
+	ValueDate time.Time `pg:"type:date"`
+	BookingDate time.Time `pg:"type:date"`
+	OverrideBy string
 }
 
+var ErrInsufficientBalance = errors.New("insufficient available balance")
+
 
 func (transaction *Transaction) Save() (*Transaction, error) {
+	err := stampPostingDates(database.Db, transaction)
+	if err != nil {
+		return nil, err
+	}
+
 	_, insertErr := database.Db.Model(transaction).Returning("*").Insert()
 
 	if insertErr != nil {
 		return nil,insertErr
 	}
 
+	err = auditOverride(database.Db, transaction)
+	if err != nil {
+		return nil, err
+	}
+
 	return transaction,nil
 }
 
+// recordTransaction saves a transaction as part of tx, stamping the time
+// and posting dates if the caller has not set them.
+func recordTransaction(tx *pg.Tx, transaction *Transaction) error {
+	if transaction.Time.IsZero() {
+		transaction.Time = time.Now()
+	}
+
+	err := stampPostingDates(tx, transaction)
+	if err != nil {
+		return err
+	}
+
+	_, insertErr := tx.Model(transaction).Returning("*").Insert()
+	if insertErr != nil {
+		return insertErr
+	}
+
+	return auditOverride(tx, transaction)
+}
+
 func AccountDeposit(accountID uint, amount float64) error {
 	tx, txErr := database.Db.Begin()
 	if txErr != nil {
 		return txErr
 	}
 
-	var account Account
-	updateResult, updateErr := tx.Model(&account).
-		Set("balance = balance + ?",amount).
-		Where("id = ?",accountID).
-		Returning("*").
-		Update(&account)
-
-	if updateErr != nil {
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
 		tx.Rollback()
-		return updateErr
+		return err
 	}
 
-	if updateResult.RowsAffected() == 0 {
+	err = accountCredit(tx, account, amount)
+	if err != nil {
 		tx.Rollback()
-		return errors.New("account does not exists")
+		return err
 	}
 
 	tx.Commit()
 	return nil
 }
 
-func AccountWithdrawal(accountID uint, amount float64) error {
+// accountCredit adds amount to an account already locked by lockAccount.
+func accountCredit(tx *pg.Tx, account *Account, amount float64) error {
+	if err := account.CanCredit(); err != nil {
+		return err
+	}
+
+	_, updateErr := tx.Model(account).
+		Set("balance = balance + ?",amount).
+		WherePK().
+		Returning("*").
+		Update()
+
+	return updateErr
+}
+
+func AccountWithdrawal(accountID uint, amount float64, initiatedBy uint) error {
 	tx, txErr := database.Db.Begin()
 	if txErr != nil {
 		return txErr
 	}
 
-	exists, err := tx.Model(&Account{}).Where("id = ?", accountID).Exists()
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-    if !exists {
-        tx.Rollback()
-        return errors.New("account does not exist")
-    }
+	err := accountWithdrawal(tx, accountID, amount, initiatedBy)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// accountWithdrawal debits the account inside tx as long as its status and
+// operating mode allow initiatedBy to debit it and the available balance
+// (balance less active holds and the minimum balance) covers it.
+// initiatedBy is 0 for debits through cards, cheques and collect approvals,
+// which do not identify the customer.
+func accountWithdrawal(tx *pg.Tx, accountID uint, amount float64, initiatedBy uint) error {
+	account, err := lockAccount(tx, "id = ?", accountID)
+	if err != nil {
+		return err
+	}
+
+	if err := account.CanDebit(); err != nil {
+		return err
+	}
 
-	var account Account
-	updateResult, updateErr := tx.Model(&account).
+	if err := checkOperatingMode(tx, account, initiatedBy, amount); err != nil {
+		return err
+	}
+
+	return debitAccount(tx, account, amount)
+}
+
+// debitAccount takes amount off the locked account, sweeping funds in from
+// its deposit first if it has a sweep rule. The caller has checked that the
+// account may be debited.
+func debitAccount(tx *pg.Tx, account *Account, amount float64) error {
+	if err := sweepIn(tx, account, amount); err != nil {
+		return err
+	}
+
+	updateResult, updateErr := tx.Model(account).
 		Set("balance = balance - ?",amount).
-		Where("id = ?",accountID).
-		Where("(balance - ?) >= ?",amount,MIN_BALANCE).
+		WherePK().
+		Where("(balance - ? - (?)) >= ?",amount,activeHoldsTotal(tx, account.ID),MIN_BALANCE).
 		Returning("*").
-		Update(&account)
+		Update()
 
 	if updateErr != nil {
-		tx.Rollback()
 		return updateErr
 	}
 
 	if updateResult.RowsAffected() == 0 {
-		tx.Rollback()
-		return errors.New("insufficient balance")
+		return ErrInsufficientBalance
 	}
 
-	tx.Commit()
 	return nil
 }
 
-func AccountTransfer(accountID uint,receiverAccountNo uuid.UUID,amount float64) error {
+func AccountTransfer(accountID uint,receiverAccountNo uuid.UUID,amount float64,initiatedBy uint) error {
 	tx, txErr := database.Db.Begin()
 	if txErr != nil {
 		return txErr
 	}
 
-	err := AccountWithdrawal(accountID, amount)
+	err := accountWithdrawal(tx, accountID, amount, initiatedBy)
 
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	var receiver Account
-	updateResult, updateErr := tx.Model(&receiver).
-		Set("balance = balance + ?",amount).
-		Where("account_number = ?",receiverAccountNo).
-		Returning("*").
-		Update(&receiver)
-
-	if updateErr != nil {
+	receiver, err := lockAccount(tx, "account_number = ?", receiverAccountNo)
+	if err != nil {
 		tx.Rollback()
-		return updateErr
+		return err
 	}
 
-	if updateResult.RowsAffected() == 0 {
+	err = accountCredit(tx, receiver, amount)
+	if err != nil {
 		tx.Rollback()
-		return errors.New("account does not exists")
+		return err
 	}
 
 	tx.Commit()
@@ -162,27 +249,92 @@ func FindTransactionByID(id uint) (*Transaction, error){
 
 }
 
-func FindAllTransactionsByAccountNumber(accNumber uuid.UUID) ([]Transaction, error){
-	account,err := FindAccountByAccountNumber(accNumber)
+// TransactionFilter narrows a list of transactions. Zero fields do not filter.
+type TransactionFilter struct {
+	From      time.Time
+	To        time.Time
+	MinAmount float64
+	MaxAmount float64
+	Type      string
+	Mode      string
+	Reference string
+	// Text is matched against the narration of a transaction: its type,
+	// mode, reference and receiving VPA.
+	Text string
+}
 
+var transactionSortKeys = sortKeys{
+	"id":     "transaction.id",
+	"time":   "transaction.time",
+	"amount": "COALESCE(transaction.amount, 0)",
+}
+
+func (transaction *Transaction) sortValue(key string) interface{} {
+	switch key {
+	case "time":
+		return transaction.Time
+	case "amount":
+		return transaction.Amount
+	}
+	return nil
+}
+
+// FindAllTransactionsByAccountNumber lists the transactions recorded against
+// an account and the transfers received into it.
+func FindAllTransactionsByAccountNumber(accNumber uuid.UUID, filter TransactionFilter, opts ListOptions) ([]Transaction, *Page, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var transactions []Transaction
-	getErr := database.Db.Model(&transactions).
-		Where("account_number = ?",accNumber).
-		WhereOr("account_id = ?",account.ID).
-		Select()
+	query := database.Db.Model(&transactions).
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("transaction.account_id = ?", account.ID).
+				WhereOr("transaction.receiver_account_number = ?", accNumber), nil
+		})
+
+	if !filter.From.IsZero() {
+		query = query.Where("transaction.time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("transaction.time < ?", filter.To)
+	}
+	if filter.MinAmount > 0 {
+		query = query.Where("transaction.amount >= ?", filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		query = query.Where("transaction.amount <= ?", filter.MaxAmount)
+	}
+	if filter.Type != "" {
+		query = query.Where("transaction.type_of_transaction = ?", filter.Type)
+	}
+	if filter.Mode != "" {
+		query = query.Where("transaction.mode_of_payment = ?", filter.Mode)
+	}
+	if filter.Reference != "" {
+		query = query.Where("transaction.reference = ?", filter.Reference)
+	}
+	if filter.Text != "" {
+		pattern := "%" + strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(filter.Text) + "%"
+		query = query.Where("concat_ws(' ', transaction.type_of_transaction, transaction.mode_of_payment, transaction.reference, transaction.receiver_vpa) ILIKE ?", pattern)
+	}
 
+	query, err = opts.paginate(query, "transaction", transactionSortKeys)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	getErr := query.Select()
 	if getErr != nil {
-		return nil,getErr
+		return nil, nil, getErr
 	}
 
-	
-	return transactions,nil
+	page, count := opts.page(len(transactions), func(i int) (interface{}, uint) {
+		return transactions[i].sortValue(opts.Sort), transactions[i].ID
+	})
 
+	return transactions[:count], page, nil
 }
 
 func DeleteAllTransactions()  error {
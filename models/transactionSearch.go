@@ -0,0 +1,122 @@
+package models
+
+import (
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const (
+	DIRECTION_DEBIT  = "debit"
+	DIRECTION_CREDIT = "credit"
+)
+
+// TransactionSearchResult is a transaction as seen from one account: whether
+// it took money out of or brought money into the account, and who was on the
+// other side. Counterparty fields are empty for cash and bank-initiated
+// transactions; CounterpartyName is only known for internal accounts.
+type TransactionSearchResult struct {
+	Transaction
+	Direction           string
+	CounterpartyAccount string
+	CounterpartyVPA     string
+	CounterpartyName    string
+}
+
+type counterparty struct {
+	ID            uint
+	AccountNumber uuid.UUID
+	Name          string
+}
+
+// findCounterparties loads the internal accounts on the other side of the
+// transactions, keyed both by id and by account number, with the name of
+// their first holder.
+func findCounterparties(ids []uint, numbers []uuid.UUID) (map[uint]*counterparty, map[uuid.UUID]*counterparty, error) {
+	byID := map[uint]*counterparty{}
+	byNumber := map[uuid.UUID]*counterparty{}
+	if len(ids) == 0 && len(numbers) == 0 {
+		return byID, byNumber, nil
+	}
+	if len(ids) == 0 {
+		ids = []uint{0}
+	}
+	if len(numbers) == 0 {
+		numbers = []uuid.UUID{uuid.Nil}
+	}
+
+	var parties []counterparty
+	_, err := database.Db.Query(&parties, `
+		SELECT a.id, a.account_number,
+			(SELECT c.name FROM customer_to_accounts m JOIN customers c ON c.id = m.customer_id
+			 WHERE m.account_id = a.id AND `+holderRoles+` ORDER BY m.id LIMIT 1) AS name
+		FROM accounts a
+		WHERE a.id IN (?) OR a.account_number IN (?)`, pg.In(ids), pg.In(numbers))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range parties {
+		byID[parties[i].ID] = &parties[i]
+		byNumber[parties[i].AccountNumber] = &parties[i]
+	}
+	return byID, byNumber, nil
+}
+
+// SearchTransactionsByAccountNumber finds the debits and credits of an
+// account and marks each with its direction and counterparty.
+func SearchTransactionsByAccountNumber(accNumber uuid.UUID, filter TransactionFilter, opts ListOptions) ([]TransactionSearchResult, *Page, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactions, page, err := FindAllTransactionsByAccountNumber(accNumber, filter, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []uint
+	var numbers []uuid.UUID
+	for _, transaction := range transactions {
+		if transaction.AccountID != account.ID {
+			ids = append(ids, transaction.AccountID)
+		} else if transaction.ReceiverAccountNumber != uuid.Nil {
+			numbers = append(numbers, transaction.ReceiverAccountNumber)
+		}
+	}
+
+	byID, byNumber, err := findCounterparties(ids, numbers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]TransactionSearchResult, len(transactions))
+	for i, transaction := range transactions {
+		result := TransactionSearchResult{
+			Transaction:     transaction,
+			Direction:       DIRECTION_CREDIT,
+			CounterpartyVPA: transaction.ReceiverVPA,
+		}
+		if transaction.signedAmount(account.ID) < 0 {
+			result.Direction = DIRECTION_DEBIT
+		}
+
+		var party *counterparty
+		if transaction.AccountID != account.ID {
+			party = byID[transaction.AccountID]
+			result.CounterpartyVPA = ""
+		} else if transaction.ReceiverAccountNumber != uuid.Nil {
+			party = byNumber[transaction.ReceiverAccountNumber]
+			result.CounterpartyAccount = MaskAccountNumber(transaction.ReceiverAccountNumber)
+		}
+		if party != nil {
+			result.CounterpartyAccount = MaskAccountNumber(party.AccountNumber)
+			result.CounterpartyName = MaskName(party.Name)
+		}
+
+		results[i] = result
+	}
+
+	return results, page, nil
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/google/uuid"
+	"github.com/shouryagautam/bankdeploy/database"
+)
+
+const VPA_HANDLE = "bankdeploy"
+
+var vpaNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,49}$`)
+
+type VPA struct {
+	ID                  uint
+	Address             string             `pg:",unique"`
+	CustomerID          uint               `pg:"on_delete:CASCADE"`
+	Customer            *Customer          `pg:"rel:has-one"`
+	CustomerToAccountID uint               `pg:"on_delete:CASCADE"`
+	CustomerToAccount   *CustomerToAccount `pg:"rel:has-one"`
+	CreatedAt           time.Time
+}
+
+// VPAResolution is what a payer sees before paying a VPA.
+type VPAResolution struct {
+	Address    string
+	HolderName string
+}
+
+// MaskName keeps the first letter of every word of a name.
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
+
+// findHolderMapping returns the mapping through which a customer holds an account.
+func findHolderMapping(customerID uint, accNumber uuid.UUID) (*CustomerToAccount, error) {
+	account, err := FindAccountByAccountNumber(accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Status == ACCOUNT_CLOSED {
+		return nil, errors.New("account is closed")
+	}
+
+	var mapping CustomerToAccount
+	getErr := database.Db.Model(&mapping).
+		Where("customer_id = ?", customerID).
+		Where("account_id = ?", account.ID).
+		Select()
+
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("customer does not hold this account")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &mapping, nil
+}
+
+// RegisterVPA creates the address name@VPA_HANDLE for a customer, pointing
+// at one of their accounts.
+func RegisterVPA(customerID uint, name string, accNumber uuid.UUID) (*VPA, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !vpaNamePattern.MatchString(name) {
+		return nil, errors.New("VPA name must be 3 to 50 letters, digits, dots, dashes or underscores")
+	}
+
+	mapping, err := findHolderMapping(customerID, accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	vpa := VPA{
+		Address:             name + "@" + VPA_HANDLE,
+		CustomerID:          customerID,
+		CustomerToAccountID: mapping.ID,
+		CreatedAt:           time.Now(),
+	}
+
+	_, insertErr := database.Db.Model(&vpa).Returning("*").Insert()
+	if insertErr != nil {
+		return nil, insertErr
+	}
+
+	return &vpa, nil
+}
+
+func FindAllVPAsByCustomerID(id uint) ([]VPA, error) {
+	var vpas []VPA
+	getErr := database.Db.Model(&vpas).
+		Relation("CustomerToAccount.Account").
+		Where("vpa.customer_id = ?", id).
+		Order("vpa.id").
+		Select()
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return vpas, nil
+}
+
+func findVPA(address string) (*VPA, error) {
+	var vpa VPA
+	getErr := database.Db.Model(&vpa).
+		Relation("Customer").
+		Relation("CustomerToAccount.Account").
+		Where("vpa.address = ?", strings.ToLower(strings.TrimSpace(address))).
+		Select()
+
+	if getErr == pg.ErrNoRows {
+		return nil, errors.New("VPA does not exist")
+	}
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	return &vpa, nil
+}
+
+// ResolveVPA returns the masked name of the holder behind a VPA.
+func ResolveVPA(address string) (*VPAResolution, error) {
+	vpa, err := findVPA(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VPAResolution{
+		Address:    vpa.Address,
+		HolderName: MaskName(vpa.Customer.Name),
+	}, nil
+}
+
+// FindAccountByVPA returns the account a VPA currently points to.
+func FindAccountByVPA(address string) (*Account, error) {
+	vpa, err := findVPA(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return vpa.CustomerToAccount.Account, nil
+}
+
+// findVPAAddressByAccountID returns the first VPA pointing at an account, or
+// an empty string if it has none.
+func findVPAAddressByAccountID(accountID uint) (string, error) {
+	var address string
+	getErr := database.Db.Model((*VPA)(nil)).
+		Column("vpa.address").
+		Join("JOIN customer_to_accounts m ON m.id = vpa.customer_to_account_id").
+		Where("m.account_id = ?", accountID).
+		Order("vpa.id").
+		Limit(1).
+		Select(&address)
+
+	if getErr == pg.ErrNoRows {
+		return "", nil
+	}
+	return address, getErr
+}
+
+// ChangeVPAAccount points a customer's VPA at another of their accounts.
+func ChangeVPAAccount(address string, customerID uint, accNumber uuid.UUID) (*VPA, error) {
+	vpa, err := findVPA(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if vpa.CustomerID != customerID {
+		return nil, errors.New("VPA belongs to another customer")
+	}
+
+	mapping, err := findHolderMapping(customerID, accNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	_, updateErr := database.Db.Model(vpa).
+		Set("customer_to_account_id = ?", mapping.ID).
+		WherePK().
+		Update()
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	return findVPA(address)
+}
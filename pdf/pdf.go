@@ -0,0 +1,146 @@
+// Package pdf writes simple text-only PDF documents: A4 pages of
+// monospaced lines, paginated automatically.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 40
+	fontSize   = 9
+	leading    = 11
+
+	// LineWidth is the number of characters that fit on a line.
+	LineWidth = (pageWidth - 2*margin) * 10 / (fontSize * 6)
+
+	linesPerPage = (pageHeight - 2*margin) / leading
+)
+
+type line struct {
+	text string
+	bold bool
+}
+
+// Document is a PDF being built line by line.
+type Document struct {
+	pages [][]line
+}
+
+func New() *Document {
+	return &Document{pages: [][]line{{}}}
+}
+
+func (doc *Document) add(text string, bold bool) {
+	page := len(doc.pages) - 1
+	if len(doc.pages[page]) == linesPerPage {
+		doc.pages = append(doc.pages, []line{})
+		page++
+	}
+	doc.pages[page] = append(doc.pages[page], line{text: text, bold: bold})
+}
+
+// Line adds a line of text, cut to LineWidth.
+func (doc *Document) Line(text string) {
+	doc.add(text, false)
+}
+
+// Heading adds a line of bold text, cut to LineWidth.
+func (doc *Document) Heading(text string) {
+	doc.add(text, true)
+}
+
+// PageBreak starts a new page.
+func (doc *Document) PageBreak() {
+	doc.pages = append(doc.pages, []line{})
+}
+
+// escape makes text safe inside a PDF string literal. Characters outside
+// printable ASCII are replaced as the standard fonts cannot show them.
+func escape(text string) string {
+	var out strings.Builder
+	count := 0
+	for _, r := range text {
+		if count == LineWidth {
+			break
+		}
+		count++
+
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r < 32 || r > 126:
+			out.WriteByte('?')
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+func (doc *Document) content(page []line) []byte {
+	var stream bytes.Buffer
+	fmt.Fprintf(&stream, "BT\n%d TL\n%d %d Td\n", leading, margin, pageHeight-margin)
+
+	font := ""
+	for _, l := range page {
+		next := "/F1"
+		if l.bold {
+			next = "/F2"
+		}
+		if next != font {
+			fmt.Fprintf(&stream, "%s %d Tf\n", next, fontSize)
+			font = next
+		}
+		fmt.Fprintf(&stream, "(%s) Tj T*\n", escape(l.text))
+	}
+
+	stream.WriteString("ET\n")
+	return stream.Bytes()
+}
+
+// Bytes renders the document.
+func (doc *Document) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 to 4 are the catalog, page tree and fonts; each page then
+	// takes two objects, the page and its content stream.
+	kids := make([]string, len(doc.pages))
+	for i := range doc.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(doc.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold >>")
+
+	for i, page := range doc.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+
+		stream := doc.content(page)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
@@ -12,6 +12,7 @@ import (
 // @BasePath /admin
 // @BasePath /manager
 // @BasePath /customer
+// @BasePath /terminal
 func Router() {
 
 	router := gin.Default()
@@ -23,6 +24,23 @@ func Router() {
 	superRoutes.PUT("/bank", handlers.UpdateBank)
 	superRoutes.DELETE("/bank/:id", handlers.DeleteBankByID)
 	superRoutes.DELETE("/bank", handlers.DeleteAllBanks)
+	superRoutes.GET("/bank/:id/business-day", handlers.GetBusinessDayByBankID)
+	superRoutes.POST("/bank/:id/eod", handlers.RunEOD)
+	superRoutes.GET("/bank/:id/eod", handlers.GetAllEODRunsByBankID)
+	superRoutes.GET("/bank/:id/eod/report", handlers.GetDayEndReport)
+	superRoutes.POST("/bank/:id/bod", handlers.StartBusinessDay)
+	superRoutes.GET("/bank/:id/dashboard", handlers.GetBankDashboard)
+	superRoutes.GET("/dashboard", handlers.GetSystemDashboard)
+	superRoutes.PUT("/ctr/rule", handlers.SaveCTRRule)
+	superRoutes.GET("/ctr/rule", handlers.GetAllCTRRules)
+	superRoutes.GET("/ctr", handlers.GetAllCTRFlags)
+	superRoutes.POST("/ctr/:id/review", handlers.ReviewCTRFlag)
+	superRoutes.POST("/ctr/report", handlers.GenerateCTRReport)
+	superRoutes.POST("/reconciliation", handlers.ReconcileBalances)
+	superRoutes.GET("/reconciliation", handlers.GetAllReconciliationRuns)
+	superRoutes.GET("/reconciliation/:id", handlers.GetReconciliationRunByID)
+	superRoutes.POST("/discrepancy/:id/repair", handlers.RequestBalanceRepair)
+	superRoutes.POST("/discrepancy/:id/approve", handlers.ApproveBalanceRepair)
 
 	adminRoutes := router.Group("/admin")
 	adminRoutes.POST("/branch", handlers.CreateBranch)
@@ -31,9 +49,14 @@ func Router() {
 	adminRoutes.PUT("/branch", handlers.UpdateBranch)
 	adminRoutes.DELETE("/branch/:id", handlers.DeleteBranchByID)
 	adminRoutes.DELETE("/branch", handlers.DeleteAllBranches)
+	adminRoutes.POST("/staff", handlers.CreateStaff)
+	adminRoutes.GET("/staff/:id", handlers.GetStaffByID)
+	adminRoutes.POST("/staff/:id/deactivate", handlers.DeactivateStaff)
 
 	managerRoutes := router.Group("/manager")
 	managerRoutes.POST("/customer", handlers.CreateCustomer)
+	managerRoutes.POST("/customer/duplicates", handlers.FindDuplicateCustomers)
+	managerRoutes.POST("/customer/:id/merge", handlers.MergeCustomers)
 	managerRoutes.POST("/account", handlers.CreateAccount)
 	managerRoutes.GET("branch/:id/account", handlers.GetAllAccountsByBranchID)
 	managerRoutes.GET("/account/:id", handlers.GetAccountById)
@@ -42,9 +65,46 @@ func Router() {
 	managerRoutes.PUT("/account", handlers.UpdateAccount)
 	managerRoutes.PUT("/customer", handlers.UpdateCustomer)
 	managerRoutes.DELETE("/account", handlers.DeleteAllAccounts)
-	managerRoutes.DELETE("/account/:id", handlers.DeleteAccountByID)
+	managerRoutes.POST("/account/:id/close", handlers.CloseAccount)
+	managerRoutes.POST("/account/backdated", handlers.PostBackDated)
 	managerRoutes.DELETE("/customer", handlers.DeleteAllCustomers)
 	managerRoutes.DELETE("/customer/:id", handlers.DeleteCustomerByID)
+	managerRoutes.POST("/account/hold", handlers.PlaceHold)
+	managerRoutes.GET("/account/:id/hold", handlers.GetAllHoldsByAccountID)
+	managerRoutes.DELETE("/account/hold/:id", handlers.ReleaseHoldByID)
+	managerRoutes.PUT("/account/:id/status", handlers.ChangeAccountStatus)
+	managerRoutes.GET("/account/:id/audit", handlers.GetAccountAuditLog)
+	managerRoutes.POST("/account/:id/reactivate", handlers.ReactivateAccount)
+	managerRoutes.GET("/branch/:id/dormant", handlers.GetDormancyReportByBranchID)
+	managerRoutes.POST("/account/:id/chequebook", handlers.IssueChequeBook)
+	managerRoutes.GET("/account/:id/chequebook", handlers.GetAllChequeBooksByAccountID)
+	managerRoutes.POST("/cheque/clear", handlers.ClearCheque)
+	managerRoutes.POST("/account/:id/card", handlers.IssueCard)
+	managerRoutes.POST("/card/unblock", handlers.ReleaseCardBlock)
+	managerRoutes.POST("/atm", handlers.CreateATM)
+	managerRoutes.GET("/branch/:id/atm", handlers.GetAllATMsByBranchID)
+	managerRoutes.POST("/atm/:id/replenish", handlers.ReplenishATM)
+	managerRoutes.GET("/atm/:id/reconcile", handlers.ReconcileATM)
+	managerRoutes.POST("/atm/dispense/:id/reverse", handlers.ReverseATMDispense)
+	managerRoutes.PUT("/account/:id/sweep", handlers.SaveSweepRule)
+	managerRoutes.GET("/account/:id/sweep", handlers.GetSweepRuleByAccountID)
+	managerRoutes.POST("/account/:id/sweep/run", handlers.RunSweep)
+	managerRoutes.POST("/account/:id/sweep/disable", handlers.DisableSweepRule)
+	managerRoutes.PUT("/account/:id/mode", handlers.SetOperatingMode)
+	managerRoutes.POST("/customer/:id/deceased", handlers.ReportDeath)
+	managerRoutes.GET("/claim/:id", handlers.GetDeceasedClaimByID)
+	managerRoutes.POST("/claim/:id/document", handlers.AddClaimDocument)
+	managerRoutes.POST("/claim/:id/approve", handlers.ApproveDeceasedClaim)
+	managerRoutes.POST("/account/:id/majority-kyc", handlers.CompleteMajorityKYC)
+	managerRoutes.GET("/branch/:id/notification", handlers.GetAllNotificationsByBranchID)
+	managerRoutes.GET("/branch/:id/dashboard", handlers.GetBranchDashboard)
+	managerRoutes.PUT("/customer/:id/tds/exemption", handlers.SaveTDSExemption)
+	managerRoutes.GET("/customer/:id/tds", handlers.GetAllTDSLedgerEntries)
+	managerRoutes.POST("/customer/:id/kyc", handlers.SubmitKYC)
+	managerRoutes.GET("/customer/:id/kyc", handlers.GetAllKYCRecordsByCustomerID)
+	managerRoutes.GET("/kyc/:id/file", handlers.GetKYCFile)
+	managerRoutes.POST("/kyc/:id/review", handlers.ReviewKYC)
+	managerRoutes.GET("/branch/:id/kyc/due", handlers.GetAllReKYCDueByBranchID)
 
 	userRoutes := router.Group("/customer")
 	userRoutes.POST("/account/deposit", handlers.Deposit)
@@ -54,9 +114,34 @@ func Router() {
 	userRoutes.GET("/:id/account", handlers.GetAllAccountsByCustomerID)
 	userRoutes.GET("/account/:number", handlers.GetAccountByAccountNumber)
 	userRoutes.GET("/account/:number/transactions", handlers.GetAllTransactionsByAccountNumber)
+	userRoutes.GET("/account/:number/transactions/search", handlers.SearchTransactionsByAccountNumber)
+	userRoutes.GET("/account/:number/statement", handlers.GetStatementByAccountNumber)
 	userRoutes.GET("/account/transactions/:id", handlers.GetTransactionByID)
-	userRoutes.PUT("/account/nominee", handlers.AddNominee)
+	userRoutes.PUT("/account/nominee", handlers.SetNominees)
 	userRoutes.DELETE("/account/:number/nominee/:id", handlers.DeleteNomineeFromAccountByID)
+	userRoutes.POST("/account/cheque/stop", handlers.StopCheque)
+	userRoutes.GET("/account/:number/cheque", handlers.GetAllChequesByAccountNumber)
+	userRoutes.GET("/account/:number/card", handlers.GetAllCardsByAccountNumber)
+	userRoutes.POST("/card/block", handlers.BlockCard)
+	userRoutes.POST("/card/unblock", handlers.UnblockCard)
+	userRoutes.PUT("/card/pin", handlers.ChangeCardPIN)
+	userRoutes.POST("/vpa", handlers.RegisterVPA)
+	userRoutes.PUT("/vpa", handlers.ChangeVPAAccount)
+	userRoutes.GET("/vpa/:address", handlers.ResolveVPA)
+	userRoutes.GET("/:id/vpa", handlers.GetAllVPAsByCustomerID)
+	userRoutes.GET("/:id/tds/certificate", handlers.GetTDSCertificate)
+	userRoutes.POST("/collect", handlers.RaiseCollectRequest)
+	userRoutes.GET("/account/:number/collect", handlers.GetAllCollectRequestsByAccountNumber)
+	userRoutes.POST("/collect/:id/approve", handlers.ApproveCollectRequest)
+	userRoutes.POST("/collect/:id/decline", handlers.DeclineCollectRequest)
+	userRoutes.GET("/account/:number/instruction", handlers.GetAllPendingInstructionsByAccountNumber)
+	userRoutes.POST("/instruction/:id/approve", handlers.ApproveInstruction)
+	userRoutes.POST("/instruction/:id/reject", handlers.RejectInstruction)
+
+	terminalRoutes := router.Group("/terminal")
+	terminalRoutes.POST("/card/authorize", handlers.AuthorizeCard)
+	terminalRoutes.POST("/card/capture/:id", handlers.CaptureCardHold)
+	terminalRoutes.POST("/atm/:id/withdraw", handlers.ATMWithdrawal)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 